@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+// apikey generates a new API key. The key is printed once on stderr and the
+// YAML entry to add under keys: in AUTH_API_KEYS_FILE on stdout; no file is
+// written.
+func main() {
+	name := flag.String("name", "", "name identifying the key owner")
+	roles := flag.String("roles", "upload", "comma separated roles, e.g. upload:partner-a/,list")
+	ttl := flag.Duration("ttl", 0, "lifetime of the key, e.g. 2160h; zero never expires")
+	flag.Parse()
+
+	if *name == "" {
+		fmt.Fprintln(os.Stderr, "apikey: -name is required")
+		os.Exit(2)
+	}
+
+	var expiresAt time.Time
+	if *ttl > 0 {
+		expiresAt = time.Now().UTC().Add(*ttl).Truncate(time.Second)
+	}
+
+	key, record, err := credentials.GenerateAPIKey(*name, strings.Split(*roles, ","), expiresAt)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "apikey:", err)
+		os.Exit(1)
+	}
+
+	entry, err := yaml.Marshal([]credentials.APIKey{record})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "apikey:", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "key (shown once): %s\n\nadd under `keys:` in AUTH_API_KEYS_FILE:\n", key)
+	fmt.Print(string(entry))
+}
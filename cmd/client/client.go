@@ -2,102 +2,480 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"log/slog"
+	"io"
+	"net/textproto"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 	"time"
 
-	"cloud.google.com/go/storage"
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
-	"github.com/ogen-go/ogen/http"
-	"gitlab.com/totalprocessing/file-upload/internal/auth"
+	ogenhttp "github.com/ogen-go/ogen/http"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
-	"gitlab.com/totalprocessing/file-upload/internal/logs"
-	"gitlab.com/totalprocessing/file-upload/internal/tracing"
-
-	"google.golang.org/api/option"
+	"gitlab.com/totalprocessing/file-upload/internal/observability"
 )
 
-type Config struct {
-	Port            string        `env:"PORT" envDefault:"8080"`
-	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"5s"`
+const usage = `usage: client [credential flags] <command> [flags] [args]
+
+commands:
+  upload [-prefix folder] [-o table|json] <file or glob>...
+  list [-prefix folder] [-all] [-o table|json]
+  download [-out path] <name>
+  delete <name>...
+  sync [-prefix folder] [-concurrency n] [-state file] [-once] <dir>
+  encrypt-credentials -out <file>
+
+credential flags:
+  -profile name             profile from the config file
+  -config file              config file (default: CLIENT_CONFIG or the user config directory)
+  -netrc file               read the login for the server host from a netrc file
+  -credentials-file file    read credentials encrypted with CLIENT_CREDENTIALS_PASSPHRASE
+  -credential-process cmd   run cmd and read JSON credentials from its output
 
-	GcsProject    string `env:"GCS_PROJECT,required,notEmpty"`
-	GcsBucketName string `env:"GCS_BUCKET_NAME,required,notEmpty"`
-	GcsLocation   string `env:"GCS_LOCATION" envDefault:"global"`
+Without credential flags credentials are read from AUTH_API_KEY, AUTH_TOKEN or
+AUTH_USERNAME/AUTH_PASSWORD, then from the config file's default profile.
+The server is read from CLIENT_URL unless a profile sets it.
+`
 
-	AuthUsername string `env:"AUTH_USERNAME,required,notEmpty"`
-	AuthPassword string `env:"AUTH_PASSWORD,required,notEmpty"`
+type Config struct {
+	AuthUsername string `env:"AUTH_USERNAME"`
+	AuthPassword string `env:"AUTH_PASSWORD"`
+	AuthAPIKey   string `env:"AUTH_API_KEY"`
+	AuthToken    string `env:"AUTH_TOKEN"`
+
+	ConfigFile            string `env:"CLIENT_CONFIG"`
+	CredentialsPassphrase string `env:"CLIENT_CREDENTIALS_PASSPHRASE"`
 
-	ClientUrl string `env:"CLIENT_URL" envDefault:"localhost:8080"`
+	ClientUrl string        `env:"CLIENT_URL" envDefault:"http://localhost:8080"`
+	Timeout   time.Duration `env:"CLIENT_TIMEOUT" envDefault:"5m"`
 }
 
 func main() {
-	logger := logs.NewPrettyLogger()
-	if err := run(logger); err != nil {
-		logger.Error("server", "error", err)
+	_ = godotenv.Load()
+
+	cfg := Config{}
+	if err := env.Parse(&cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "parsing config: %v\n", err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, cfg, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run(logger *slog.Logger) error {
-	err := godotenv.Load()
+// CLI runs one command against the API.
+type CLI struct {
+	client *fileupload.Client
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func run(ctx context.Context, cfg Config, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	var opts sourceOptions
+	fs.StringVar(&opts.Profile, "profile", "", "profile from the config file")
+	fs.StringVar(&cfg.ConfigFile, "config", cfg.ConfigFile, "config file")
+	fs.StringVar(&opts.Netrc, "netrc", "", "netrc file")
+	fs.StringVar(&opts.CredentialsFile, "credentials-file", "", "encrypted credentials file")
+	fs.StringVar(&opts.CredentialProcess, "credential-process", "", "credential command")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return errors.New("missing command")
+	}
+	command, args := fs.Arg(0), fs.Args()[1:]
+
+	switch command {
+	case "help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	case "encrypt-credentials":
+		return encryptCredentials(cfg, args, stderr)
+	}
+
+	sec, url, err := securitySource(cfg, opts)
 	if err != nil {
-		logger.Info("No .env file loaded")
+		return err
 	}
 
-	cfg := Config{}
-	if err := env.Parse(&cfg); err != nil {
-		return fmt.Errorf("parsing config: %w", err)
+	httpClient := observability.NewTracingClient()
+	httpClient.Timeout = cfg.Timeout
+
+	client, err := fileupload.NewClient(url, sec, fileupload.WithClient(httpClient))
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	cli := &CLI{client: client, stdout: stdout, stderr: stderr}
+	switch command {
+	case "upload":
+		return cli.upload(ctx, args)
+	case "list":
+		return cli.list(ctx, args)
+	case "download":
+		return cli.download(ctx, args)
+	case "delete":
+		return cli.delete(ctx, args)
+	case "sync", "watch":
+		return cli.sync(ctx, args)
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func (c *CLI) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+func (c *CLI) upload(ctx context.Context, args []string) error {
+	fs := c.flags("upload")
+	prefix := fs.String("prefix", "", "folder to store the files under")
+	output := fs.String("o", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	paths, err := expandGlobs(fs.Args())
+	if err != nil {
+		return err
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	var uploaded []fileupload.UploadResponse
+	var failed int
+	for _, path := range paths {
+		res, err := c.uploadFile(ctx, path, *prefix, c.stderr)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", path, err)
+			failed++
+			continue
+		}
+		uploaded = append(uploaded, *res)
+	}
 
-	// ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
-	// defer stop()
+	if err := c.printUploads(uploaded, *output); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d uploads failed", failed, len(paths))
+	}
+	return nil
+}
 
-	gcsClient, err := storage.NewClient(ctx,
-		option.WithQuotaProject(cfg.GcsProject))
+// uploadFile uploads one local file, reporting progress to progress unless it
+// is nil.
+func (c *CLI) uploadFile(ctx context.Context, path, prefix string, progress io.Writer) (*fileupload.UploadResponse, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("failed to create GCS client: %w", err)
+		return nil, err
 	}
-	defer gcsClient.Close()
+	defer f.Close()
 
-	// Create security source with your credentials
-	sec := &auth.BasicAuthProvider{
-		Username: cfg.AuthUsername,
-		Password: cfg.AuthPassword,
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
 	}
 
-	httpClient := tracing.NewTracingClient()
+	name := filepath.Base(path)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType(name))
 
-	client, err := fileupload.NewClient(
-		cfg.ClientUrl,
-		sec,
-		fileupload.WithClient(httpClient),
-	)
+	var body io.Reader = f
+	if progress != nil {
+		body = newProgressReader(f, info.Size(), name, progress)
+	}
+
+	req := &fileupload.UploadFileReq{
+		File: ogenhttp.MultipartFile{
+			Name:   name,
+			File:   body,
+			Size:   info.Size(),
+			Header: header,
+		},
+	}
+	if prefix != "" {
+		req.Prefix = fileupload.NewOptString(prefix)
+	}
+
+	res, err := c.client.UploadFile(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return nil, apiError(err)
+	}
+
+	switch res := res.(type) {
+	case *fileupload.UploadResponseHeaders:
+		return &res.Response, nil
+	case *fileupload.UploadFileBadRequest:
+		return nil, errorFromResponse(fileupload.Error(*res))
+	case *fileupload.UploadFileUnauthorized:
+		return nil, errorFromResponse(fileupload.Error(*res))
+	case *fileupload.UploadFileForbidden:
+		return nil, errorFromResponse(fileupload.Error(*res))
+	case *fileupload.UploadFileInternalServerError:
+		return nil, errorFromResponse(fileupload.Error(*res))
+	case *fileupload.ErrorHeaders:
+		return nil, errorFromResponse(res.Response)
+	default:
+		return nil, fmt.Errorf("unexpected response %T", res)
+	}
+}
+
+func (c *CLI) list(ctx context.Context, args []string) error {
+	fs := c.flags("list")
+	prefix := fs.String("prefix", "", "only list files under this folder")
+	all := fs.Bool("all", false, "fetch every page")
+	pageSize := fs.Int("page-size", 100, "files per page")
+	output := fs.String("o", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	params := fileupload.ListFilesParams{PageSize: fileupload.NewOptInt(*pageSize)}
+	if *prefix != "" {
+		params.Prefix = fileupload.NewOptString(*prefix)
+	}
+
+	var files []fileupload.FileInfo
+	for {
+		page, err := c.client.ListFiles(ctx, params)
+		if err != nil {
+			return apiError(err)
+		}
+		files = append(files, page.Files...)
+
+		next, ok := page.NextPageToken.Get()
+		if !ok || !*all {
+			if ok {
+				fmt.Fprintln(c.stderr, "more files available, use -all to list them")
+			}
+			break
+		}
+		params.PageToken = fileupload.NewOptString(next)
+	}
+
+	if *output == "json" {
+		return writeJSON(c.stdout, files)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tUPDATED")
+	for _, f := range files {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", f.Name, f.Size, f.Updated.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func (c *CLI) download(ctx context.Context, args []string) error {
+	fs := c.flags("download")
+	out := fs.String("out", "", "output file or directory, - for stdout (default: the file's base name)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("download takes exactly one file name")
 	}
+	name := fs.Arg(0)
 
-	otelProviders, err := tracing.SetupOTelSDK(ctx, "http-file-upload", "v1")
+	res, err := c.client.DownloadFile(ctx, fileupload.DownloadFileParams{Name: name})
 	if err != nil {
+		return apiError(err)
+	}
+
+	if *out == "-" {
+		_, err := io.Copy(c.stdout, res.Response.Data)
 		return err
 	}
 
-	// handle shutdown properly so nothing leaks
-	defer func() {
-		err = errors.Join(err, otelProviders.Shutdown(context.Background()))
-	}()
+	path := *out
+	if path == "" {
+		path = filepath.Base(name)
+	} else if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, filepath.Base(name))
+	}
 
-	file := http.MultipartFile{}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, res.Response.Data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
 
-	client.UploadFile(ctx, &fileupload.UploadFileReq{
-		File: file,
-	})
+	fmt.Fprintf(c.stderr, "%s: %d bytes written to %s\n", name, n, path)
+	return nil
+}
 
+func (c *CLI) delete(ctx context.Context, args []string) error {
+	fs := c.flags("delete")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("delete needs at least one file name")
+	}
+
+	var failed int
+	for _, name := range fs.Args() {
+		if err := c.client.DeleteFile(ctx, fileupload.DeleteFileParams{Name: name}); err != nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", name, apiError(err))
+			failed++
+			continue
+		}
+		fmt.Fprintf(c.stdout, "deleted %s\n", name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d deletes failed", failed, fs.NArg())
+	}
 	return nil
 }
+
+func (c *CLI) printUploads(uploaded []fileupload.UploadResponse, output string) error {
+	if output == "json" {
+		return writeJSON(c.stdout, uploaded)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILENAME\tSIZE\tPATH\tUPLOADED\tSENSITIVE COLUMNS")
+	for _, u := range uploaded {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%d\n", u.Filename, u.FileSize, u.Gcspath, u.UploadTime.Format(time.RFC3339), len(u.SensitiveColumns))
+	}
+	return w.Flush()
+}
+
+// writeJSON encodes values with the generated encoders, which the standard
+// library cannot use for ogen's optional types.
+func writeJSON[T any, PT interface {
+	*T
+	json.Marshaler
+}](w io.Writer, values []T) error {
+	raw := make([]json.RawMessage, 0, len(values))
+	for i := range values {
+		b, err := PT(&values[i]).MarshalJSON()
+		if err != nil {
+			return err
+		}
+		raw = append(raw, b)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(raw)
+}
+
+// expandGlobs resolves each argument as a glob, keeping literal paths that
+// match nothing so the open error names them.
+func expandGlobs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no files given")
+	}
+
+	var paths []string
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			matches = []string{arg}
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+func contentType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return "text/csv"
+	case ".xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// apiError unwraps the server's error response so its details are shown.
+func apiError(err error) error {
+	var statusErr *fileupload.ErrorStatusCodeWithHeaders
+	if errors.As(err, &statusErr) {
+		return errorFromResponse(statusErr.Response)
+	}
+	return err
+}
+
+// ResponseError is an error response from the server.
+type ResponseError struct {
+	Code    int
+	Message string
+	Details []string
+}
+
+func (e *ResponseError) Error() string {
+	if len(e.Details) == 0 {
+		return fmt.Sprintf("%d %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("%d %s: %s", e.Code, e.Message, strings.Join(e.Details, "; "))
+}
+
+func errorFromResponse(res fileupload.Error) error {
+	return &ResponseError{Code: int(res.Code), Message: res.Message, Details: res.Details}
+}
+
+// progressReader reports upload progress on stderr.
+type progressReader struct {
+	r        io.Reader
+	total    int64
+	read     int64
+	name     string
+	out      io.Writer
+	lastShow time.Time
+}
+
+func newProgressReader(r io.Reader, total int64, name string, out io.Writer) *progressReader {
+	return &progressReader{r: r, total: total, name: name, out: out}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	done := errors.Is(err, io.EOF)
+	if done || time.Since(p.lastShow) > 200*time.Millisecond {
+		p.lastShow = time.Now()
+		percent := 100
+		if p.total > 0 {
+			percent = int(p.read * 100 / p.total)
+		}
+		fmt.Fprintf(p.out, "\r%s %3d%% (%d/%d bytes)", p.name, percent, p.read, p.total)
+		if done {
+			fmt.Fprintln(p.out)
+		}
+	}
+	return n, err
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -13,7 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gitlab.com/totalprocessing/file-upload/internal/auth"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
-	"gitlab.com/totalprocessing/file-upload/internal/tracing"
+	"gitlab.com/totalprocessing/file-upload/internal/observability"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/propagation"
@@ -39,6 +42,7 @@ func TestTracingHeaders(t *testing.T) {
             "filename": "test.txt",
             "fileSize": 1234,
             "bucket": "test-bucket",
+            "gcspath": "gs://test-bucket/test.txt",
             "uploadTime": "2023-01-01T00:00:00Z"
         }`
 		w.Write([]byte(response))
@@ -73,7 +77,7 @@ func TestTracingHeaders(t *testing.T) {
 	client, err := fileupload.NewClient(
 		cfg.ClientUrl,
 		sec,
-		fileupload.WithClient(tracing.NewTracingClient()),
+		fileupload.WithClient(observability.NewTracingClient()),
 	)
 	require.NoError(t, err, "Failed to create client")
 
@@ -111,7 +115,7 @@ func TestBasicAuthFailure(t *testing.T) {
 	client, err := fileupload.NewClient(
 		"http://localhost:8080",
 		sec,
-		fileupload.WithClient(tracing.NewTracingClient()),
+		fileupload.WithClient(observability.NewTracingClient()),
 	)
 	require.NoError(t, err)
 
@@ -143,7 +147,7 @@ func TestTracingPropagation(t *testing.T) {
 		propagation.Baggage{},
 	))
 
-	client := tracing.NewTracingClient()
+	client := observability.NewTracingClient()
 	// Create a span with a known trace ID and span ID
 	tracer := tp.Tracer("test")
 	ctx, span := tracer.Start(context.Background(), "test-span")
@@ -160,12 +164,15 @@ func TestTracingPropagation(t *testing.T) {
 	t.Logf("Trace State: %s", spanContext.TraceState())
 	t.Logf("Baggage: %s", baggage.FromContext(ctx).String())
 
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
 	// Create HTTP request with the context
-	req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com", nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL, nil)
 
 	// Use client
 	resp, err := client.Do(req)
-	assert.NoError(t, err)
+	require.NoError(t, err)
 	defer resp.Body.Close()
 
 	// Log propagated headers
@@ -196,3 +203,103 @@ func TestTracingPropagation(t *testing.T) {
 	baggageHeader := req.Header.Get("baggage")
 	assert.NotEmpty(t, "test-key=test-value", baggageHeader, "Baggage header mismatch")
 }
+
+func newUploadServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		assert.Equal(t, "text/csv", header.Header.Get("Content-Type"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+            "filename": "` + header.Filename + `",
+            "fileSize": 12,
+            "bucket": "test-bucket",
+            "gcspath": "gs://test-bucket/` + header.Filename + `",
+            "uploadTime": "2023-01-01T00:00:00Z"
+        }`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func writeTestFile(t *testing.T, name string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte("a,b\n1,2\n3,4\n"), 0o600))
+	return path
+}
+
+func TestRunUploadTable(t *testing.T) {
+	ts := newUploadServer(t)
+	path := writeTestFile(t, "report.csv")
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), Config{ClientUrl: ts.URL}, []string{"upload", path}, &stdout, &stderr)
+	require.NoError(t, err)
+
+	assert.Contains(t, stdout.String(), "FILENAME")
+	assert.Contains(t, stdout.String(), "gs://test-bucket/report.csv")
+	assert.Contains(t, stderr.String(), "100%")
+}
+
+func TestRunUploadJSONGlob(t *testing.T) {
+	ts := newUploadServer(t)
+	path := writeTestFile(t, "a.csv")
+	writeTestFile(t, "b.csv")
+
+	var stdout, stderr bytes.Buffer
+	pattern := filepath.Join(filepath.Dir(path), "*.csv")
+	err := run(context.Background(), Config{ClientUrl: ts.URL}, []string{"upload", "-o", "json", pattern}, &stdout, &stderr)
+	require.NoError(t, err)
+
+	var uploaded []map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &uploaded))
+	require.Len(t, uploaded, 1)
+	assert.Equal(t, "a.csv", uploaded[0]["filename"])
+}
+
+func TestRunErrorDetails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": 400, "message": "invalid file", "details": ["row 2: missing column"]}`))
+	}))
+	defer ts.Close()
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), Config{ClientUrl: ts.URL}, []string{"upload", writeTestFile(t, "bad.csv")}, &stdout, &stderr)
+	require.Error(t, err)
+	assert.Contains(t, stderr.String(), "row 2: missing column")
+
+	err = run(context.Background(), Config{ClientUrl: ts.URL}, []string{"list"}, &stdout, &stderr)
+	require.ErrorContains(t, err, "row 2: missing column")
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), Config{ClientUrl: "http://localhost"}, []string{"rename"}, &stdout, &stderr)
+	require.Error(t, err)
+	assert.Contains(t, stderr.String(), "usage:")
+}
+
+func TestRunProfile(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "fuk_test", r.Header.Get("X-API-Key"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"files": []}`))
+	}))
+	defer ts.Close()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("profiles:\n  test:\n    url: "+ts.URL+"\n    apiKey: fuk_test\n"), 0o600))
+
+	// The profile's URL replaces CLIENT_URL.
+	var stdout, stderr bytes.Buffer
+	cfg := Config{ClientUrl: "http://localhost:1", ConfigFile: configFile}
+	err := run(context.Background(), cfg, []string{"-profile", "test", "list"}, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "NAME")
+
+	err = run(context.Background(), cfg, []string{"-netrc", "a", "-credential-process", "b", "list"}, &stdout, &stderr)
+	require.ErrorContains(t, err, "only one of")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"gitlab.com/totalprocessing/file-upload/internal/auth"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+// sourceOptions select where credentials are read from; at most one of
+// Netrc, CredentialsFile and CredentialProcess may be set.
+type sourceOptions struct {
+	Profile           string
+	Netrc             string
+	CredentialsFile   string
+	CredentialProcess string
+}
+
+// securitySource returns the credential source and server URL. Explicit
+// credential flags win over a profile named with -profile, which wins over
+// the AUTH_* variables, which win over the config file's default profile.
+func securitySource(cfg Config, opts sourceOptions) (fileupload.SecuritySource, string, error) {
+	explicit := 0
+	for _, v := range []string{opts.Netrc, opts.CredentialsFile, opts.CredentialProcess} {
+		if v != "" {
+			explicit++
+		}
+	}
+	if explicit > 1 {
+		return nil, "", errors.New("use only one of -netrc, -credentials-file and -credential-process")
+	}
+
+	configFile := cfg.ConfigFile
+	if configFile == "" {
+		configFile = auth.DefaultConfigPath()
+	}
+
+	serverURL := cfg.ClientUrl
+	var profile *auth.Profile
+	if opts.Profile != "" || (explicit == 0 && !hasEnvCredentials(cfg) && fileExists(configFile)) {
+		p, err := auth.LoadProfile(configFile, opts.Profile)
+		if err != nil {
+			return nil, "", err
+		}
+		if p.URL != "" {
+			serverURL = p.URL
+		}
+		profile = &p
+	}
+
+	host, err := hostOf(serverURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case opts.Netrc != "":
+		return auth.NewNetrcSource(opts.Netrc, host), serverURL, nil
+	case opts.CredentialsFile != "":
+		if cfg.CredentialsPassphrase == "" {
+			return nil, "", errors.New("-credentials-file needs CLIENT_CREDENTIALS_PASSPHRASE")
+		}
+		return auth.NewEncryptedFileSource(opts.CredentialsFile, []byte(cfg.CredentialsPassphrase)), serverURL, nil
+	case opts.CredentialProcess != "":
+		return auth.NewCommandSource(opts.CredentialProcess), serverURL, nil
+	case profile != nil:
+		return profile.Source(host), serverURL, nil
+	default:
+		return &auth.BasicAuthProvider{
+			Username: cfg.AuthUsername,
+			Password: cfg.AuthPassword,
+			APIKey:   cfg.AuthAPIKey,
+			Token:    cfg.AuthToken,
+		}, serverURL, nil
+	}
+}
+
+// encryptCredentials writes the AUTH_* credentials to an encrypted
+// credentials file for use with -credentials-file.
+func encryptCredentials(cfg Config, args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("encrypt-credentials", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("out", "", "file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("encrypt-credentials needs -out")
+	}
+	if cfg.CredentialsPassphrase == "" {
+		return errors.New("set CLIENT_CREDENTIALS_PASSPHRASE to encrypt credentials")
+	}
+	if !hasEnvCredentials(cfg) {
+		return errors.New("set AUTH_API_KEY, AUTH_TOKEN or AUTH_USERNAME/AUTH_PASSWORD to the credentials to encrypt")
+	}
+
+	data, err := auth.EncryptCredentials(auth.Credentials{
+		Username: cfg.AuthUsername,
+		Password: cfg.AuthPassword,
+		APIKey:   cfg.AuthAPIKey,
+		Token:    cfg.AuthToken,
+	}, []byte(cfg.CredentialsPassphrase))
+	if err != nil {
+		return fmt.Errorf("encrypting credentials: %w", err)
+	}
+	if err := os.WriteFile(*out, data, 0o600); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "credentials written to %s\n", *out)
+	return nil
+}
+
+func hasEnvCredentials(cfg Config) bool {
+	return cfg.AuthUsername != "" || cfg.AuthAPIKey != "" || cfg.AuthToken != ""
+}
+
+func hostOf(serverURL string) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+	return u.Hostname(), nil
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	doneDir       = "done"
+	failedDir     = "failed"
+	stateFileName = ".upload-state.json"
+	maxAttempts   = 3
+)
+
+// syncer uploads the CSV and XLSX files in a directory, moving each to done/
+// or failed/ once processed. Uploaded files are recorded by content hash in a
+// state file so that a file is never uploaded twice, even across restarts.
+type syncer struct {
+	cli    *CLI
+	dir    string
+	prefix string
+	state  *syncState
+	retry  time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+	// uploading holds the content hashes being uploaded; the channel is
+	// closed when the upload is over.
+	uploading map[string]chan struct{}
+	failed    int
+}
+
+func (c *CLI) sync(ctx context.Context, args []string) error {
+	fs := c.flags("sync")
+	prefix := fs.String("prefix", "", "folder to store the files under")
+	concurrency := fs.Int("concurrency", 4, "number of parallel uploads")
+	statePath := fs.String("state", "", "state file (default: <dir>/"+stateFileName+")")
+	settle := fs.Duration("settle", 2*time.Second, "time a file must be unchanged before it is uploaded")
+	once := fs.Bool("once", false, "upload the files already in the directory and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("sync takes exactly one directory")
+	}
+	if *concurrency < 1 {
+		return errors.New("concurrency must be at least 1")
+	}
+
+	dir := fs.Arg(0)
+	for _, sub := range []string{doneDir, failedDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return fmt.Errorf("creating %s directory: %w", sub, err)
+		}
+	}
+
+	if *statePath == "" {
+		*statePath = filepath.Join(dir, stateFileName)
+	}
+	state, err := loadSyncState(*statePath)
+	if err != nil {
+		return err
+	}
+
+	s := &syncer{
+		cli:       c,
+		dir:       dir,
+		prefix:    *prefix,
+		state:     state,
+		retry:     2 * time.Second,
+		inFlight:  map[string]bool{},
+		uploading: map[string]chan struct{}{},
+	}
+
+	// Start watching before listing the directory so files created in
+	// between are not missed.
+	var watcher *fsnotify.Watcher
+	if !*once {
+		watcher, err = fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("creating watcher: %w", err)
+		}
+		defer watcher.Close()
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("watching %s: %w", dir, err)
+		}
+	}
+
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for range *concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range queue {
+				s.process(ctx, path)
+			}
+		}()
+	}
+	stop := sync.OnceFunc(func() {
+		close(queue)
+		wg.Wait()
+	})
+	defer stop()
+
+	existing, err := s.pending()
+	if err != nil {
+		return err
+	}
+	for _, path := range existing {
+		if !s.enqueue(ctx, queue, path) {
+			return ctx.Err()
+		}
+	}
+
+	if *once {
+		stop()
+		if s.failed > 0 {
+			return fmt.Errorf("%d uploads failed", s.failed)
+		}
+		return nil
+	}
+
+	s.printf(c.stderr, "watching %s\n", dir)
+	return s.watch(ctx, watcher, queue, *settle)
+}
+
+// watch queues files once they have stopped changing for settle.
+func (s *syncer) watch(ctx context.Context, watcher *fsnotify.Watcher, queue chan<- string, settle time.Duration) error {
+	ready := make(chan string)
+	timers := map[string]*time.Timer{}
+	defer func() {
+		for _, t := range timers {
+			t.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write) == 0 || !s.eligible(event.Name) {
+				continue
+			}
+			if t, ok := timers[event.Name]; ok {
+				t.Reset(settle)
+				continue
+			}
+			path := event.Name
+			timers[path] = time.AfterFunc(settle, func() {
+				select {
+				case ready <- path:
+				case <-ctx.Done():
+				}
+			})
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			s.printf(s.cli.stderr, "watch error: %v\n", err)
+		case path := <-ready:
+			delete(timers, path)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			if !s.enqueue(ctx, queue, path) {
+				return nil
+			}
+		}
+	}
+}
+
+// pending lists the eligible files already in the directory.
+func (s *syncer) pending() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.dir, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(s.dir, entry.Name())
+		if entry.Type().IsRegular() && s.eligible(path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func (s *syncer) eligible(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") || filepath.Dir(path) != filepath.Clean(s.dir) {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".xlsx":
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueue hands path to a worker unless it is already being processed. It
+// returns false if ctx is done.
+func (s *syncer) enqueue(ctx context.Context, queue chan<- string, path string) bool {
+	s.mu.Lock()
+	if s.inFlight[path] {
+		s.mu.Unlock()
+		return true
+	}
+	s.inFlight[path] = true
+	s.mu.Unlock()
+
+	select {
+	case queue <- path:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *syncer) process(ctx context.Context, path string) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.inFlight, path)
+		s.mu.Unlock()
+	}()
+
+	name := filepath.Base(path)
+	hash, err := hashFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		s.fail(path, err)
+		return
+	}
+
+	// Files with the same content are uploaded one at a time, so a copy
+	// finds the record of the first upload instead of sending it again.
+	if !s.claim(ctx, hash) {
+		return
+	}
+	defer s.release(hash)
+
+	if record, ok := s.state.get(hash); ok {
+		s.printf(s.cli.stdout, "%s: already uploaded as %s\n", name, record.Gcspath)
+		s.move(path, doneDir)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := s.cli.uploadFile(ctx, path, s.prefix, nil)
+		if err == nil {
+			record := syncRecord{File: name, Gcspath: res.Gcspath, UploadedAt: time.Now().UTC()}
+			if err := s.state.put(hash, record); err != nil {
+				s.printf(s.cli.stderr, "%s: uploaded but not recorded: %v\n", name, err)
+			}
+			s.printf(s.cli.stdout, "%s: uploaded to %s\n", name, res.Gcspath)
+			s.move(path, doneDir)
+			return
+		}
+
+		// Leave the file in place so it is picked up again after a restart.
+		if ctx.Err() != nil {
+			return
+		}
+		if attempt == maxAttempts || !retryable(err) {
+			s.fail(path, err)
+			return
+		}
+
+		s.printf(s.cli.stderr, "%s: %v, retrying\n", name, err)
+		select {
+		case <-time.After(s.retry * time.Duration(attempt)):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// claim reserves hash for the caller, waiting while another worker uploads the
+// same content. It returns false if ctx is done first.
+func (s *syncer) claim(ctx context.Context, hash string) bool {
+	for {
+		s.mu.Lock()
+		done, busy := s.uploading[hash]
+		if !busy {
+			s.uploading[hash] = make(chan struct{})
+			s.mu.Unlock()
+			return true
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (s *syncer) release(hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.uploading[hash])
+	delete(s.uploading, hash)
+}
+
+func (s *syncer) fail(path string, err error) {
+	s.mu.Lock()
+	s.failed++
+	s.mu.Unlock()
+
+	s.printf(s.cli.stderr, "%s: %v\n", filepath.Base(path), err)
+	s.move(path, failedDir)
+}
+
+// move renames path into the sub directory, adding a timestamp if a file of
+// the same name is already there.
+func (s *syncer) move(path, sub string) {
+	name := filepath.Base(path)
+	target := filepath.Join(s.dir, sub, name)
+	if _, err := os.Stat(target); err == nil {
+		ext := filepath.Ext(name)
+		target = filepath.Join(s.dir, sub, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), time.Now().UnixNano(), ext))
+	}
+	if err := os.Rename(path, target); err != nil {
+		s.printf(s.cli.stderr, "%s: moving to %s: %v\n", name, sub, err)
+	}
+}
+
+func (s *syncer) printf(w io.Writer, format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(w, format, args...)
+}
+
+// retryable reports whether an upload may succeed if tried again: network
+// errors, rate limiting and server errors are retried, rejected files are not.
+func retryable(err error) bool {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return false
+	}
+	var resErr *ResponseError
+	if errors.As(err, &resErr) {
+		return resErr.Code == 429 || resErr.Code >= 500
+	}
+	return true
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type syncRecord struct {
+	File       string    `json:"file"`
+	Gcspath    string    `json:"gcspath"`
+	UploadedAt time.Time `json:"uploadedAt"`
+}
+
+// syncState is the set of uploaded files keyed by SHA-256 of their content.
+type syncState struct {
+	path string
+
+	mu       sync.Mutex
+	Uploaded map[string]syncRecord `json:"uploaded"`
+}
+
+func loadSyncState(path string) (*syncState, error) {
+	state := &syncState{path: path, Uploaded: map[string]syncRecord{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parsing state file %s: %w", path, err)
+	}
+	if state.Uploaded == nil {
+		state.Uploaded = map[string]syncRecord{}
+	}
+	return state, nil
+}
+
+func (s *syncState) get(hash string) (syncRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.Uploaded[hash]
+	return record, ok
+}
+
+// put records an upload and writes the state file, replacing it atomically.
+func (s *syncState) put(hash string, record syncRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Uploaded[hash] = record
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replacing state file: %w", err)
+	}
+	return nil
+}
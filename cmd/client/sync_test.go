@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncOnce(t *testing.T) {
+	var uploads atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		uploads.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		if header.Filename == "bad.csv" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": 400, "message": "invalid file", "details": ["empty header"]}`))
+			return
+		}
+		w.Write([]byte(`{
+            "filename": "` + header.Filename + `",
+            "fileSize": 12,
+            "bucket": "test-bucket",
+            "gcspath": "gs://test-bucket/` + header.Filename + `",
+            "uploadTime": "2023-01-01T00:00:00Z"
+        }`))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.csv"), []byte("a,b\n1,2\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.csv"), []byte("a,b\n3,4\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.csv"), []byte(",\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skip me"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	cfg := Config{ClientUrl: ts.URL}
+	err := run(context.Background(), cfg, []string{"sync", "-once", dir}, &stdout, &stderr)
+	require.ErrorContains(t, err, "1 uploads failed")
+	assert.Equal(t, int32(3), uploads.Load())
+
+	assert.FileExists(t, filepath.Join(dir, "done", "a.csv"))
+	assert.FileExists(t, filepath.Join(dir, "done", "b.csv"))
+	assert.FileExists(t, filepath.Join(dir, "failed", "bad.csv"))
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+	assert.Contains(t, stderr.String(), "empty header")
+
+	// A file with content that was already uploaded is not sent again, even
+	// by a new run.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a-copy.csv"), []byte("a,b\n1,2\n"), 0o600))
+	stdout.Reset()
+	err = run(context.Background(), cfg, []string{"sync", "-once", dir}, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), uploads.Load())
+	assert.Contains(t, stdout.String(), "already uploaded as gs://test-bucket/a.csv")
+	assert.FileExists(t, filepath.Join(dir, "done", "a-copy.csv"))
+}
+
+func TestSyncUploadsIdenticalFilesOnce(t *testing.T) {
+	var uploads atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		uploads.Add(1)
+		// Keep the upload in flight while the other workers pick up copies.
+		time.Sleep(100 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+            "filename": "` + header.Filename + `",
+            "fileSize": 8,
+            "bucket": "test-bucket",
+            "gcspath": "gs://test-bucket/` + header.Filename + `",
+            "uploadTime": "2023-01-01T00:00:00Z"
+        }`))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.csv", "b.csv", "c.csv", "d.csv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("a,b\n1,2\n"), 0o600))
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), Config{ClientUrl: ts.URL}, []string{"sync", "-once", "-concurrency", "4", dir}, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), uploads.Load())
+	for _, name := range []string{"a.csv", "b.csv", "c.csv", "d.csv"} {
+		assert.FileExists(t, filepath.Join(dir, "done", name))
+	}
+	assert.Equal(t, 3, bytes.Count(stdout.Bytes(), []byte("already uploaded")))
+}
+
+func TestSyncWatch(t *testing.T) {
+	ts := newUploadServer(t)
+	dir := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, Config{ClientUrl: ts.URL}, []string{"sync", "-settle", "50ms", dir}, &stdout, &stderr)
+	}()
+
+	// Wait for the watcher to start before dropping a file in.
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "done"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.csv"), []byte("a,b\n1,2\n"), 0o600))
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "done", "new.csv"))
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Contains(t, stdout.String(), "new.csv: uploaded to gs://test-bucket/new.csv")
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, retryable(&ResponseError{Code: 503}))
+	assert.True(t, retryable(&ResponseError{Code: 429}))
+	assert.False(t, retryable(&ResponseError{Code: 400}))
+	assert.False(t, retryable(&os.PathError{Op: "open", Path: "x", Err: os.ErrNotExist}))
+}
@@ -2,24 +2,38 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"cloud.google.com/go/bigquery"
 	"cloud.google.com/go/storage"
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 	"github.com/ogen-go/ogen/ogenerrors"
+	"gitlab.com/totalprocessing/file-upload/internal/audit"
+	"gitlab.com/totalprocessing/file-upload/internal/bqload"
+	"gitlab.com/totalprocessing/file-upload/internal/cors"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/events"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
 	"gitlab.com/totalprocessing/file-upload/internal/gcs"
 	"gitlab.com/totalprocessing/file-upload/internal/handlers"
+	"gitlab.com/totalprocessing/file-upload/internal/health"
+	"gitlab.com/totalprocessing/file-upload/internal/lockout"
 	"gitlab.com/totalprocessing/file-upload/internal/logs"
 	"gitlab.com/totalprocessing/file-upload/internal/observability"
+	"gitlab.com/totalprocessing/file-upload/internal/pii"
+	"gitlab.com/totalprocessing/file-upload/internal/ratelimit"
+	"gitlab.com/totalprocessing/file-upload/internal/ui"
+	"gitlab.com/totalprocessing/file-upload/internal/webhook"
 
 	"google.golang.org/api/option"
 )
@@ -27,20 +41,113 @@ import (
 type Config struct {
 	Port            string        `env:"PORT" envDefault:"8080"`
 	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"5s"`
+	// ShutdownDrainDelay keeps serving after readiness flips so load
+	// balancers notice before the listener closes.
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" envDefault:"0s"`
+	HealthCacheTTL     time.Duration `env:"HEALTH_CACHE_TTL" envDefault:"30s"`
 
 	GcsProject    string `env:"GCS_PROJECT,required,notEmpty"`
 	GcsBucketName string `env:"GCS_BUCKET_NAME,required,notEmpty"`
 	GcsLocation   string `env:"GCS_LOCATION" envDefault:"global"`
 
-	AuthUsername string `env:"AUTH_USERNAME,required,notEmpty"`
-	AuthPassword string `env:"AUTH_PASSWORD,required,notEmpty"`
+	AuditSink      string `env:"AUDIT_SINK"`
+	AuditFile      string `env:"AUDIT_FILE" envDefault:"audit.jsonl"`
+	AuditGCSBucket string `env:"AUDIT_GCS_BUCKET"`
+	AuditGCSPrefix string `env:"AUDIT_GCS_PREFIX" envDefault:"audit"`
+
+	WebhooksFile       string        `env:"WEBHOOKS_FILE"`
+	WebhookQueueDir    string        `env:"WEBHOOK_QUEUE_DIR" envDefault:"webhooks"`
+	WebhookMaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"10"`
+	WebhookBackoffBase time.Duration `env:"WEBHOOK_BACKOFF_BASE" envDefault:"10s"`
+	WebhookBackoffMax  time.Duration `env:"WEBHOOK_BACKOFF_MAX" envDefault:"1h"`
+	WebhookTimeout     time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+
+	EventsPublisher string        `env:"EVENTS_PUBLISHER"`
+	EventsSource    string        `env:"EVENTS_SOURCE" envDefault:"/file-upload"`
+	EventsOutboxDir string        `env:"EVENTS_OUTBOX_DIR" envDefault:"outbox"`
+	EventsRetryBase time.Duration `env:"EVENTS_RETRY_BASE" envDefault:"1s"`
+	EventsRetryMax  time.Duration `env:"EVENTS_RETRY_MAX" envDefault:"1m"`
+	PubSubProject   string        `env:"PUBSUB_PROJECT"`
+	PubSubTopic     string        `env:"PUBSUB_TOPIC"`
+	NATSURL         string        `env:"NATS_URL"`
+	NATSSubject     string        `env:"NATS_SUBJECT"`
+	KafkaBrokers    []string      `env:"KAFKA_BROKERS" envSeparator:","`
+	KafkaTopic      string        `env:"KAFKA_TOPIC"`
+
+	BigQueryLoadsFile string `env:"BIGQUERY_LOADS_FILE"`
+	BigQueryProject   string `env:"BIGQUERY_PROJECT"`
+	BigQueryLocation  string `env:"BIGQUERY_LOCATION"`
+
+	EncryptionMode       string `env:"ENCRYPTION_MODE"`
+	EncryptionKeyFile    string `env:"ENCRYPTION_KEY_FILE"`
+	EncryptionKMSKeyName string `env:"ENCRYPTION_KMS_KEY_NAME"`
+	EncryptionRulesFile  string `env:"ENCRYPTION_RULES_FILE"`
+
+	PIIAction   string   `env:"PII_ACTION"`
+	PIIKinds    []string `env:"PII_KINDS" envSeparator:","`
+	PIITokenKey string   `env:"PII_TOKEN_KEY"`
+
+	AuthUsername            string        `env:"AUTH_USERNAME"`
+	AuthPassword            string        `env:"AUTH_PASSWORD"`
+	AuthUsersFile           string        `env:"AUTH_USERS_FILE"`
+	AuthUsersReloadInterval time.Duration `env:"AUTH_USERS_RELOAD_INTERVAL" envDefault:"10s"`
+	AuthAPIKeysFile         string        `env:"AUTH_API_KEYS_FILE"`
+
+	JWTJWKSURL     string            `env:"JWT_JWKS_URL"`
+	JWTIssuer      string            `env:"JWT_ISSUER"`
+	JWTAudiences   []string          `env:"JWT_AUDIENCES" envSeparator:","`
+	JWTEmailClaim  string            `env:"JWT_EMAIL_CLAIM" envDefault:"email"`
+	JWTGroupsClaim string            `env:"JWT_GROUPS_CLAIM" envDefault:"groups"`
+	JWTGroupRoles  map[string]string `env:"JWT_GROUP_ROLES" envSeparator:";" envKeyValSeparator:"="`
+
+	AuthLockoutThreshold int           `env:"AUTH_LOCKOUT_THRESHOLD" envDefault:"5"`
+	AuthLockoutBaseDelay time.Duration `env:"AUTH_LOCKOUT_BASE_DELAY" envDefault:"1s"`
+	AuthLockoutMaxDelay  time.Duration `env:"AUTH_LOCKOUT_MAX_DELAY" envDefault:"15m"`
+	AuthLockoutWindow    time.Duration `env:"AUTH_LOCKOUT_WINDOW" envDefault:"15m"`
+	AuthLockoutRedisURL  string        `env:"AUTH_LOCKOUT_REDIS_URL"`
+	TrustedProxies       []string      `env:"TRUSTED_PROXIES" envSeparator:","`
+
+	RateLimitRPS    float64 `env:"RATE_LIMIT_RPS" envDefault:"0"`
+	RateLimitBurst  int     `env:"RATE_LIMIT_BURST" envDefault:"0"`
+	QuotaDailyBytes int64   `env:"QUOTA_DAILY_BYTES" envDefault:"0"`
+	QuotaDailyFiles int64   `env:"QUOTA_DAILY_FILES" envDefault:"0"`
+
+	CORSAllowedOrigins   []string      `env:"CORS_ALLOWED_ORIGINS" envSeparator:"," envDefault:"*"`
+	CORSAllowedMethods   []string      `env:"CORS_ALLOWED_METHODS" envSeparator:"," envDefault:"GET,POST,DELETE"`
+	CORSAllowedHeaders   []string      `env:"CORS_ALLOWED_HEADERS" envSeparator:"," envDefault:"Authorization,Content-Type,X-API-Key"`
+	CORSExposedHeaders   []string      `env:"CORS_EXPOSED_HEADERS" envSeparator:"," envDefault:"Retry-After,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Bytes-Remaining,X-RateLimit-Files-Remaining,X-RateLimit-Reset"`
+	CORSAllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" envDefault:"false"`
+	CORSMaxAge           time.Duration `env:"CORS_MAX_AGE" envDefault:"10m"`
+
+	TLSCertFile     string            `env:"TLS_CERT_FILE"`
+	TLSKeyFile      string            `env:"TLS_KEY_FILE"`
+	TLSClientCAFile string            `env:"TLS_CLIENT_CA_FILE"`
+	TLSClientRoles  map[string]string `env:"TLS_CLIENT_ROLES" envSeparator:";" envKeyValSeparator:"="`
 
 	FileUploadLimit int `env:"FILE_UPLOAD_LIMIT" envDefault:"10"`
 
 	Environment       string  `env:"ENVIRONMENT" envDefault:"development"`
+	LogLevel          string  `env:"LOG_LEVEL"`
+	LogFormat         string  `env:"LOG_FORMAT"`
 	TracingEnabled    bool    `env:"TRACING_ENABLED" envDefault:"false"`
+	TracingExporter   string  `env:"TRACING_EXPORTER"`
 	TracingEndpoint   string  `env:"TRACING_ENDPOINT"`
 	TracingSampleRate float64 `env:"TRACING_SAMPLE_RATE" envDefault:"1.0"`
+
+	OTLPProtocol    string            `env:"OTLP_PROTOCOL"`
+	OTLPHeaders     map[string]string `env:"OTLP_HEADERS" envSeparator:"," envKeyValSeparator:"="`
+	OTLPCAFile      string            `env:"OTLP_CA_FILE"`
+	OTLPCompression string            `env:"OTLP_COMPRESSION"`
+	OTLPInsecure    bool              `env:"OTLP_INSECURE" envDefault:"false"`
+
+	MetricsPrometheusEnabled bool          `env:"METRICS_PROMETHEUS_ENABLED" envDefault:"false"`
+	MetricsPath              string        `env:"METRICS_PATH" envDefault:"/metrics"`
+	MetricsAddr              string        `env:"METRICS_ADDR"`
+	MetricsExporter          string        `env:"METRICS_EXPORTER"`
+	MetricsExportInterval    time.Duration `env:"METRICS_EXPORT_INTERVAL"`
+
+	LogsExporter string     `env:"LOGS_EXPORTER"`
+	LogsLevel    slog.Level `env:"LOGS_EXPORT_LEVEL" envDefault:"INFO"`
 }
 
 func main() {
@@ -48,18 +155,37 @@ func main() {
 
 	cfg := Config{}
 	if err := env.Parse(&cfg); err != nil {
-		logs.NewLogger("local").Error("server", "error", fmt.Errorf("parsing config: %w", err))
+		logs.NewLogger("local", "").Error("server", "error", fmt.Errorf("parsing config: %w", err))
 		os.Exit(1)
 	}
 
-	logger := logs.NewLogger(cfg.Environment)
-	if err := run(cfg, logger); err != nil {
+	level := &slog.LevelVar{}
+	level.Set(logs.DefaultLevel(cfg.Environment))
+	if cfg.LogLevel != "" {
+		if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+			logs.NewLogger("local", "").Error("server", "error", fmt.Errorf("parsing LOG_LEVEL: %w", err))
+			os.Exit(1)
+		}
+	}
+
+	logger, err := logs.New(os.Stdout, logs.Config{
+		Environment: cfg.Environment,
+		Format:      cfg.LogFormat,
+		Level:       level,
+		GCPProject:  cfg.GcsProject,
+	})
+	if err != nil {
+		logs.NewLogger("local", "").Error("server", "error", fmt.Errorf("parsing LOG_FORMAT: %w", err))
+		os.Exit(1)
+	}
+
+	if err := run(cfg, logger, level); err != nil {
 		logger.Error("server", "error", err)
 		os.Exit(1)
 	}
 }
 
-func run(cfg Config, logger *slog.Logger) (err error) {
+func run(cfg Config, logger *slog.Logger, level *slog.LevelVar) (err error) {
 	logger.Info(
 		"configuration loaded",
 		"port", cfg.Port,
@@ -67,13 +193,58 @@ func run(cfg Config, logger *slog.Logger) (err error) {
 		"gcs_bucket", cfg.GcsBucketName,
 		"file_upload_limit_mb", cfg.FileUploadLimit,
 		"environment", cfg.Environment,
+		"log_level", level.Level().String(),
+		"encryption_mode", cfg.EncryptionMode,
+		"pii_action", cfg.PIIAction,
+		"audit_sink", cfg.AuditSink,
+		"tls_enabled", cfg.TLSCertFile != "",
+		"mtls_enabled", cfg.TLSClientCAFile != "",
 		"tracing_enabled", cfg.TracingEnabled,
+		"tracing_exporter", cfg.TracingExporter,
 		"tracing_endpoint", cfg.TracingEndpoint,
+		"otlp_protocol", cfg.OTLPProtocol,
+		"metrics_prometheus", cfg.MetricsPrometheusEnabled,
+		"metrics_addr", cfg.MetricsAddr,
+		"metrics_exporter", cfg.MetricsExporter,
+		"logs_exporter", cfg.LogsExporter,
+		"events_publisher", cfg.EventsPublisher,
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Set up telemetry before the storage client so its OpenTelemetry
+	// instrumentation picks up the tracer provider and GCS calls show up as
+	// children of the upload spans.
+	telemetry, err := observability.New(observability.Config{
+		ServiceName:       "http-file-upload",
+		ServiceVersion:    "v1",
+		Environment:       cfg.Environment,
+		Logger:            logger,
+		TracingEnabled:    cfg.TracingEnabled,
+		TracingExporter:   cfg.TracingExporter,
+		TracingSampleRate: cfg.TracingSampleRate,
+		MetricsPrometheus: cfg.MetricsPrometheusEnabled,
+		MetricsExporter:   cfg.MetricsExporter,
+		MetricsInterval:   cfg.MetricsExportInterval,
+		LogsExporter:      cfg.LogsExporter,
+		LogsLevel:         cfg.LogsLevel,
+		OTLP: observability.OTLPConfig{
+			Protocol:    cfg.OTLPProtocol,
+			Endpoint:    cfg.TracingEndpoint,
+			Headers:     cfg.OTLPHeaders,
+			CAFile:      cfg.OTLPCAFile,
+			Compression: cfg.OTLPCompression,
+			Insecure:    cfg.OTLPInsecure,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to setup observability: %w", err)
+	}
+	if telemetry.LogHandler != nil {
+		logger = logs.Fanout(logger, telemetry.LogHandler)
+	}
+
 	gcsClient, err := storage.NewClient(ctx,
 		option.WithQuotaProject(cfg.GcsProject),
 	)
@@ -82,14 +253,80 @@ func run(cfg Config, logger *slog.Logger) (err error) {
 	}
 	defer gcsClient.Close()
 
-	sec := handlers.NewSecurityHandler(logger,
-		cfg.AuthUsername,
-		cfg.AuthPassword,
-	)
+	secCfg, err := loadCredentials(cfg, logger)
+	if err != nil {
+		return err
+	}
+	if secCfg.Users != nil {
+		go secCfg.Users.Watch(ctx, cfg.AuthUsersReloadInterval)
+	}
+	if secCfg.APIKeys != nil {
+		go secCfg.APIKeys.Watch(ctx, cfg.AuthUsersReloadInterval)
+	}
+
+	lockoutStore, closeLockoutStore, err := newLockoutStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeLockoutStore()
+
+	secCfg.Lockout, err = lockout.New(lockout.Config{
+		Threshold: cfg.AuthLockoutThreshold,
+		BaseDelay: cfg.AuthLockoutBaseDelay,
+		MaxDelay:  cfg.AuthLockoutMaxDelay,
+		Window:    cfg.AuthLockoutWindow,
+	}, lockoutStore)
+	if err != nil {
+		return fmt.Errorf("failed to configure lockout: %w", err)
+	}
+
+	sec := handlers.NewStoreSecurityHandler(logger, secCfg)
+
+	var auditRecorder *audit.Recorder
+	if cfg.AuditSink != "" {
+		sink, err := audit.NewSink(ctx, audit.Config{
+			Sink:         cfg.AuditSink,
+			File:         cfg.AuditFile,
+			GCSBucket:    cfg.AuditGCSBucket,
+			GCSPrefix:    cfg.AuditGCSPrefix,
+			UploadBucket: cfg.GcsBucketName,
+		}, gcsClient)
+		if err != nil {
+			return fmt.Errorf("failed to configure audit sink: %w", err)
+		}
+		if gcsSink, ok := sink.(*audit.GCSSink); ok && !gcsSink.Locked() {
+			logger.Warn("audit bucket retention policy is not locked; audit objects can be deleted once it is removed", "bucket", cfg.AuditGCSBucket)
+		}
+		auditRecorder = audit.NewRecorder(sink, logger)
+		defer auditRecorder.Close()
+	}
 
 	maxUploadSizeBytes := int64(cfg.FileUploadLimit) * 1024 * 1024
 
-	h := handlers.NewUploadHandler(logger, gcs.GcsClient{
+	encryption, err := gcs.LoadEncryptionConfig(gcs.EncryptionRule{
+		Mode:       gcs.EncryptionMode(cfg.EncryptionMode),
+		KeyFile:    cfg.EncryptionKeyFile,
+		KMSKeyName: cfg.EncryptionKMSKeyName,
+	}, cfg.EncryptionRulesFile)
+	if err != nil {
+		return fmt.Errorf("failed to load encryption config: %w", err)
+	}
+
+	piiKinds := make([]pii.Kind, 0, len(cfg.PIIKinds))
+	for _, kind := range cfg.PIIKinds {
+		piiKinds = append(piiKinds, pii.Kind(strings.ToLower(strings.TrimSpace(kind))))
+	}
+
+	piiScanner, err := pii.New(pii.Config{
+		Action:   pii.Action(cfg.PIIAction),
+		Kinds:    piiKinds,
+		TokenKey: []byte(cfg.PIITokenKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to configure pii scanner: %w", err)
+	}
+
+	bucket := gcs.GcsClient{
 		Logger:    logger,
 		GcsClient: gcsClient,
 		GcsConfig: gcs.GcsConfig{
@@ -97,22 +334,103 @@ func run(cfg Config, logger *slog.Logger) (err error) {
 			GcsLocation:        cfg.GcsLocation,
 			GcsBucketName:      cfg.GcsBucketName,
 			MaxUploadSizeBytes: maxUploadSizeBytes,
+			Encryption:         encryption,
+			PII:                piiScanner,
 		},
-	})
+	}
+	h := handlers.NewUploadHandler(logger, bucket)
+	h.LogLevel = level
 
-	telemetry, err := observability.New(observability.Config{
-		ServiceName:       "http-file-upload",
-		ServiceVersion:    "v1",
-		Environment:       cfg.Environment,
-		Logger:            logger,
-		TracingEnabled:    cfg.TracingEnabled,
-		TracingEndpoint:   cfg.TracingEndpoint,
-		TracingSampleRate: cfg.TracingSampleRate,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to setup observability: %w", err)
+	if cfg.WebhooksFile != "" {
+		subs, err := webhook.LoadSubscriptions(cfg.WebhooksFile)
+		if err != nil {
+			return fmt.Errorf("failed to load webhook subscriptions: %w", err)
+		}
+		dispatcher, err := webhook.New(webhook.Config{
+			QueueDir:    cfg.WebhookQueueDir,
+			MaxAttempts: cfg.WebhookMaxAttempts,
+			BackoffBase: cfg.WebhookBackoffBase,
+			BackoffMax:  cfg.WebhookBackoffMax,
+			Timeout:     cfg.WebhookTimeout,
+		}, subs, logger)
+		if err != nil {
+			return fmt.Errorf("failed to configure webhooks: %w", err)
+		}
+		h.Webhooks = dispatcher
+		go dispatcher.Run(ctx)
 	}
 
+	if cfg.BigQueryLoadsFile != "" {
+		rules, err := bqload.LoadRules(cfg.BigQueryLoadsFile)
+		if err != nil {
+			return fmt.Errorf("failed to load bigquery load rules: %w", err)
+		}
+		bigQueryProject := cfg.BigQueryProject
+		if bigQueryProject == "" {
+			bigQueryProject = cfg.GcsProject
+		}
+		bqClient, err := bigquery.NewClient(ctx, bigQueryProject)
+		if err != nil {
+			return fmt.Errorf("failed to create BigQuery client: %w", err)
+		}
+		defer bqClient.Close()
+		bqClient.Location = cfg.BigQueryLocation
+		h.Loads = bqload.New(bqClient, cfg.GcsBucketName, rules, func(object string) bool {
+			return !encryption.ModeFor(object).ReadableByGoogle()
+		})
+	}
+
+	if cfg.EventsPublisher != "" {
+		pubSubProject := cfg.PubSubProject
+		if pubSubProject == "" {
+			pubSubProject = cfg.GcsProject
+		}
+		publisher, err := events.NewPublisher(ctx, events.Config{
+			Publisher:     cfg.EventsPublisher,
+			PubSubProject: pubSubProject,
+			PubSubTopic:   cfg.PubSubTopic,
+			NATSURL:       cfg.NATSURL,
+			NATSSubject:   cfg.NATSSubject,
+			KafkaBrokers:  cfg.KafkaBrokers,
+			KafkaTopic:    cfg.KafkaTopic,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure event publisher: %w", err)
+		}
+		outbox, err := events.NewOutbox(events.OutboxConfig{
+			Dir:       cfg.EventsOutboxDir,
+			RetryBase: cfg.EventsRetryBase,
+			RetryMax:  cfg.EventsRetryMax,
+		}, publisher, logger)
+		if err != nil {
+			publisher.Close()
+			return fmt.Errorf("failed to configure event outbox: %w", err)
+		}
+		h.Events = outbox
+		h.EventSource = cfg.EventsSource
+
+		// Stop relaying before closing the publisher; unsent events stay in
+		// the outbox for the next start.
+		relayCtx, stopRelay := context.WithCancel(ctx)
+		relayDone := make(chan struct{})
+		go func() {
+			defer close(relayDone)
+			outbox.Run(relayCtx)
+		}()
+		defer func() {
+			stopRelay()
+			<-relayDone
+			if err := outbox.Close(); err != nil {
+				logger.Error("closing event publisher failed", "error", err)
+			}
+		}()
+	}
+
+	checker := health.New(health.Config{CacheTTL: cfg.HealthCacheTTL, Logger: logger})
+	checker.Add("gcs", bucket.CheckBucket)
+	checker.Add("credentials", credentialsCheck(secCfg))
+	checker.AddOptional("tracing", telemetry.TracingHealth)
+
 	receivedShutdownSignal := false
 
 	defer func() {
@@ -129,7 +447,14 @@ func run(cfg Config, logger *slog.Logger) (err error) {
 	}()
 
 	fileUploadServer, err := fileupload.NewServer(h, sec,
-		fileupload.WithMiddleware(),
+		fileupload.WithMiddleware(
+			handlers.RateLimit(logger, ratelimit.New(ratelimit.Config{
+				RequestsPerSecond: cfg.RateLimitRPS,
+				Burst:             cfg.RateLimitBurst,
+				DailyBytes:        cfg.QuotaDailyBytes,
+				DailyFiles:        cfg.QuotaDailyFiles,
+			})),
+		),
 		fileupload.WithErrorHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
 			logger.ErrorContext(ctx, "server error", "error", err)
 			ogenerrors.DefaultErrorHandler(ctx, w, r, err)
@@ -143,10 +468,67 @@ func run(cfg Config, logger *slog.Logger) (err error) {
 		return fmt.Errorf("failed to create server: %v", err)
 	}
 
+	corsPolicy, err := cors.New(cors.Config{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		ExposedHeaders:   cfg.CORSExposedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to configure cors: %w", err)
+	}
+
+	uiHandler := ui.Handler(ui.Config{MaxUploadBytes: maxUploadSizeBytes})
+	mux := http.NewServeMux()
+	mux.Handle("GET /healthz", checker.Liveness())
+	mux.Handle("GET /readyz", checker.Readiness())
+	// Metrics are unauthenticated, so they get their own listener when
+	// METRICS_ADDR is set and are only on the public port otherwise.
+	var metricsServer *http.Server
+	if telemetry.MetricsHandler != nil {
+		if cfg.MetricsAddr == "" {
+			mux.Handle("GET "+cfg.MetricsPath, telemetry.MetricsHandler)
+		} else {
+			metricsMux := http.NewServeMux()
+			metricsMux.Handle("GET "+cfg.MetricsPath, telemetry.MetricsHandler)
+			metricsServer = &http.Server{
+				Addr:         cfg.MetricsAddr,
+				Handler:      metricsMux,
+				ReadTimeout:  10 * time.Second,
+				WriteTimeout: 10 * time.Second,
+			}
+		}
+	}
+	mux.Handle("/ui", uiHandler)
+	mux.Handle(ui.Prefix, uiHandler)
+	auditOperation := func(r *http.Request) string {
+		route, ok := fileUploadServer.FindPath(r.Method, r.URL)
+		if !ok {
+			return ""
+		}
+		return route.OperationID()
+	}
+	trustedProxies, err := handlers.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("failed to configure trusted proxies: %w", err)
+	}
+	mux.Handle("/", handlers.WithClientIP(
+		handlers.WithAudit(handlers.WithResponseHeaders(fileUploadServer), auditRecorder, auditOperation),
+		trustedProxies,
+	))
+
+	tlsConfig, err := loadTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+
 	// ------- SERVER START
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
-		Handler:      fileUploadServer,
+		Handler:      corsPolicy.Handler(mux),
+		TLSConfig:    tlsConfig,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
@@ -155,10 +537,58 @@ func run(cfg Config, logger *slog.Logger) (err error) {
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 	defer signal.Stop(shutdown)
 
-	serverErrors := make(chan error, 1)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	// SIGUSR1 switches between debug and the configured level.
+	toggleDebug := make(chan os.Signal, 1)
+	signal.Notify(toggleDebug, syscall.SIGUSR1)
+	defer signal.Stop(toggleDebug)
+
+	go func() {
+		configured := level.Level()
+		for range toggleDebug {
+			next := slog.LevelDebug
+			if level.Level() == slog.LevelDebug {
+				next = configured
+			}
+			level.Set(next)
+			logger.Warn("log level changed", "to", next.String(), "signal", "SIGUSR1")
+		}
+	}()
+
+	go func() {
+		for range reload {
+			if secCfg.Users != nil {
+				if err := secCfg.Users.Reload(); err != nil {
+					logger.Error("credentials reload failed; keeping previous users", "error", err)
+				}
+			}
+			if secCfg.APIKeys != nil {
+				if err := secCfg.APIKeys.Reload(); err != nil {
+					logger.Error("api keys reload failed; keeping previous keys", "error", err)
+				}
+			}
+		}
+	}()
+
+	serverErrors := make(chan error, 2)
+
+	if metricsServer != nil {
+		go func() {
+			logger.Info("metrics", "available", metricsServer.Addr+cfg.MetricsPath)
+			serverErrors <- metricsServer.ListenAndServe()
+		}()
+		defer metricsServer.Close()
+	}
 
 	go func() {
 		logger.Info("application", "available", fmt.Sprintf("localhost%s", server.Addr))
+		if server.TLSConfig != nil {
+			serverErrors <- server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+			return
+		}
 		serverErrors <- server.ListenAndServe()
 	}()
 
@@ -173,6 +603,9 @@ func run(cfg Config, logger *slog.Logger) (err error) {
 		receivedShutdownSignal = true
 		logger.Info("shutdown", "status", "shutdown started", "signal", sig)
 
+		checker.ShutDown()
+		time.Sleep(cfg.ShutdownDrainDelay)
+
 		// Prevent infinite waiting
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 		defer cancel()
@@ -185,3 +618,123 @@ func run(cfg Config, logger *slog.Logger) (err error) {
 
 	return nil
 }
+
+// loadCredentials prefers AUTH_USERS_FILE over the single
+// AUTH_USERNAME/AUTH_PASSWORD pair, and loads AUTH_API_KEYS_FILE, the JWT
+// verifier and client certificate mapping if configured. At least one scheme
+// must be configured.
+func loadCredentials(cfg Config, logger *slog.Logger) (handlers.SecurityConfig, error) {
+	var secCfg handlers.SecurityConfig
+
+	switch {
+	case cfg.AuthUsersFile != "":
+		if cfg.AuthUsername != "" {
+			logger.Warn("AUTH_USERS_FILE is set; ignoring AUTH_USERNAME and AUTH_PASSWORD")
+		}
+		users, err := credentials.Load(cfg.AuthUsersFile, logger)
+		if err != nil {
+			return secCfg, fmt.Errorf("failed to load credentials: %w", err)
+		}
+		secCfg.Users = users
+	case cfg.AuthUsername != "" && cfg.AuthPassword != "":
+		secCfg.Users = credentials.NewSingleUser(cfg.AuthUsername, cfg.AuthPassword)
+	}
+
+	if cfg.AuthAPIKeysFile != "" {
+		apiKeys, err := credentials.LoadAPIKeys(cfg.AuthAPIKeysFile, logger)
+		if err != nil {
+			return secCfg, fmt.Errorf("failed to load api keys: %w", err)
+		}
+		secCfg.APIKeys = apiKeys
+	}
+
+	if cfg.JWTJWKSURL != "" {
+		groupRoles := make(map[string][]string, len(cfg.JWTGroupRoles))
+		for group, roles := range cfg.JWTGroupRoles {
+			groupRoles[group] = strings.Split(roles, ",")
+		}
+
+		verifier, err := credentials.NewJWTVerifier(credentials.JWTConfig{
+			JWKSURL:     cfg.JWTJWKSURL,
+			Issuer:      cfg.JWTIssuer,
+			Audiences:   cfg.JWTAudiences,
+			EmailClaim:  cfg.JWTEmailClaim,
+			GroupsClaim: cfg.JWTGroupsClaim,
+			GroupRoles:  groupRoles,
+			Leeway:      30 * time.Second,
+		})
+		if err != nil {
+			return secCfg, fmt.Errorf("failed to configure jwt verification: %w", err)
+		}
+		secCfg.JWT = verifier
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		identityRoles := make(map[string][]string, len(cfg.TLSClientRoles))
+		for identity, roles := range cfg.TLSClientRoles {
+			identityRoles[identity] = strings.Split(roles, ",")
+		}
+
+		certs, err := credentials.NewClientCertMapper(identityRoles)
+		if err != nil {
+			return secCfg, fmt.Errorf("failed to configure client certificates: %w", err)
+		}
+		secCfg.Certs = certs
+	}
+
+	if secCfg.Users == nil && secCfg.APIKeys == nil && secCfg.JWT == nil && secCfg.Certs == nil {
+		return secCfg, errors.New("no credentials configured: set AUTH_USERS_FILE, AUTH_USERNAME and AUTH_PASSWORD, AUTH_API_KEYS_FILE, JWT_JWKS_URL or TLS_CLIENT_CA_FILE")
+	}
+
+	return secCfg, nil
+}
+
+// credentialsCheck fails readiness while a users file is loaded but empty.
+func credentialsCheck(secCfg handlers.SecurityConfig) health.Check {
+	return func(ctx context.Context) error {
+		if secCfg.Users != nil && secCfg.Users.Len() == 0 {
+			return errors.New("no users loaded")
+		}
+		return nil
+	}
+}
+
+// loadTLSConfig returns nil when TLS_CERT_FILE is unset. With
+// TLS_CLIENT_CA_FILE, client certificates are verified when presented but not
+// required, so the other schemes keep working on the same listener.
+func loadTLSConfig(cfg Config) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" {
+		if cfg.TLSClientCAFile != "" {
+			return nil, errors.New("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		return nil, nil
+	}
+	if cfg.TLSKeyFile == "" {
+		return nil, errors.New("TLS_CERT_FILE requires TLS_KEY_FILE")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSClientCAFile != "" {
+		pool, err := credentials.LoadCertPool(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client ca bundle: %w", err)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+// newLockoutStore shares lockouts through AUTH_LOCKOUT_REDIS_URL when set and
+// otherwise keeps them in memory per instance.
+func newLockoutStore(cfg Config) (lockout.Store, func(), error) {
+	if cfg.AuthLockoutRedisURL == "" {
+		return lockout.NewMemoryStore(), func() {}, nil
+	}
+
+	store, err := lockout.NewRedisStore(cfg.AuthLockoutRedisURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure lockout store: %w", err)
+	}
+	return store, func() { _ = store.Close() }, nil
+}
@@ -0,0 +1,151 @@
+// Package audit records who did what to which object, for compliance. Events
+// go to a dedicated sink, independent of the application log.
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Outcomes of an audited request.
+const (
+	OutcomeSuccess = "success"
+	// OutcomeDenied covers failed authentication, authorization, lockouts
+	// and rate limits.
+	OutcomeDenied = "denied"
+	OutcomeFailed = "failed"
+)
+
+var (
+	ErrUnknownSink       = errors.New("unknown audit sink")
+	ErrNoRetentionPolicy = errors.New("audit bucket has no retention policy")
+)
+
+// Event is one audited request.
+type Event struct {
+	Time       time.Time `json:"time"`
+	Operation  string    `json:"operation"`
+	Principal  string    `json:"principal,omitempty"`
+	AuthMethod string    `json:"auth_method,omitempty"`
+	Object     string    `json:"object,omitempty"`
+	Size       int64     `json:"size,omitempty"`
+	Checksum   string    `json:"checksum,omitempty"`
+	ClientIP   string    `json:"client_ip,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	TraceID    string    `json:"trace_id,omitempty"`
+	Status     int       `json:"status"`
+	Outcome    string    `json:"outcome"`
+	// Reason explains a denial, e.g. invalid_credentials or forbidden.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Sink stores events. Implementations must only ever append.
+type Sink interface {
+	Write(ctx context.Context, event Event) error
+	Close() error
+}
+
+// Recorder writes events to a sink. A nil Recorder records nothing.
+type Recorder struct {
+	sink   Sink
+	logger *slog.Logger
+}
+
+// NewRecorder returns a recorder that logs sink failures to logger.
+func NewRecorder(sink Sink, logger *slog.Logger) *Recorder {
+	return &Recorder{sink: sink, logger: logger}
+}
+
+// Record writes event. Failures are logged rather than returned, since the
+// request has already been answered.
+func (r *Recorder) Record(ctx context.Context, event Event) {
+	if r == nil {
+		return
+	}
+	if err := r.sink.Write(ctx, event); err != nil {
+		r.logger.ErrorContext(ctx, "audit event not recorded",
+			"operation", event.Operation,
+			"principal", event.Principal,
+			"object", event.Object,
+			"error", err,
+		)
+	}
+}
+
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	return r.sink.Close()
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying event, to be completed with Update
+// while the request is handled.
+func NewContext(ctx context.Context, event *Event) context.Context {
+	return context.WithValue(ctx, contextKey{}, event)
+}
+
+// Update applies fn to the event in ctx, if any, and sets its trace id from
+// the span in ctx when still empty.
+func Update(ctx context.Context, fn func(*Event)) {
+	event, ok := ctx.Value(contextKey{}).(*Event)
+	if !ok {
+		return
+	}
+	if sc := trace.SpanContextFromContext(ctx); event.TraceID == "" && sc.HasTraceID() {
+		event.TraceID = sc.TraceID().String()
+	}
+	fn(event)
+}
+
+// Outcome classifies an HTTP status code.
+func Outcome(status int) string {
+	switch {
+	case status < 400:
+		return OutcomeSuccess
+	case status == 401, status == 403, status == 429:
+		return OutcomeDenied
+	default:
+		return OutcomeFailed
+	}
+}
+
+// Config selects a sink for NewSink.
+type Config struct {
+	// Sink is file, gcs or stderr.
+	Sink string
+	File string
+	// GCS stores one object per event under GCSPrefix in GCSBucket, which
+	// must not be UploadBucket: API clients can list, read, delete and
+	// upload objects there.
+	GCSBucket    string
+	GCSPrefix    string
+	UploadBucket string
+}
+
+func (c Config) validate() error {
+	switch c.Sink {
+	case "file":
+		if c.File == "" {
+			return errors.New("audit file sink requires a path")
+		}
+	case "gcs":
+		if c.GCSBucket == "" {
+			return errors.New("audit gcs sink requires a bucket")
+		}
+		if c.GCSBucket == c.UploadBucket {
+			return errors.New("audit gcs sink requires a bucket separate from the upload bucket")
+		}
+	case "stderr":
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownSink, c.Sink)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/gcs/gcstest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFileSinkAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for _, principal := range []string{"alice", "bob"} {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(context.Background(), Event{
+			Time:      time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+			Operation: "uploadFile",
+			Principal: principal,
+			Status:    200,
+			Outcome:   OutcomeSuccess,
+		}))
+		require.NoError(t, sink.Close())
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var principals []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		principals = append(principals, event.Principal)
+	}
+	require.Equal(t, []string{"alice", "bob"}, principals)
+}
+
+func TestUpdateSetsTraceID(t *testing.T) {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	event := &Event{}
+	ctx := NewContext(context.Background(), event)
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	Update(ctx, func(e *Event) { e.Object = "partner-a/report.csv" })
+	require.Equal(t, "partner-a/report.csv", event.Object)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", event.TraceID)
+
+	// Without an event in the context Update does nothing.
+	Update(context.Background(), func(e *Event) { t.Fatal("unexpected update") })
+}
+
+func TestOutcome(t *testing.T) {
+	require.Equal(t, OutcomeSuccess, Outcome(204))
+	require.Equal(t, OutcomeDenied, Outcome(401))
+	require.Equal(t, OutcomeDenied, Outcome(403))
+	require.Equal(t, OutcomeDenied, Outcome(429))
+	require.Equal(t, OutcomeFailed, Outcome(400))
+	require.Equal(t, OutcomeFailed, Outcome(500))
+}
+
+func TestNewSinkValidates(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewSink(ctx, Config{Sink: "kafka"}, nil)
+	require.ErrorIs(t, err, ErrUnknownSink)
+
+	_, err = NewSink(ctx, Config{Sink: "file"}, nil)
+	require.Error(t, err)
+
+	_, err = NewSink(ctx, Config{Sink: "gcs"}, nil)
+	require.Error(t, err)
+
+	_, err = NewSink(ctx, Config{Sink: "gcs", GCSBucket: "uploads", UploadBucket: "uploads"}, nil)
+	require.Error(t, err)
+}
+
+func TestGCSSinkRequiresRetentionPolicy(t *testing.T) {
+	ctx := context.Background()
+	srv := gcstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client(ctx)
+	require.NoError(t, err)
+	defer client.Close()
+
+	cfg := Config{Sink: "gcs", GCSBucket: "audit", GCSPrefix: "audit", UploadBucket: "uploads"}
+	_, err = NewSink(ctx, cfg, client)
+	require.ErrorIs(t, err, ErrNoRetentionPolicy)
+
+	srv.SetBucket("audit", gcstest.Bucket{RetentionPeriod: 24 * time.Hour, RetentionLocked: true})
+	sink, err := NewSink(ctx, cfg, client)
+	require.NoError(t, err)
+	require.True(t, sink.(*GCSSink).Locked())
+
+	require.NoError(t, sink.Write(ctx, Event{Time: time.Now(), Operation: "uploadFile"}))
+	attrs, err := client.Bucket("audit").Objects(ctx, &storage.Query{Prefix: "audit/"}).Next()
+	require.NoError(t, err)
+	require.Equal(t, "application/json", attrs.ContentType)
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(context.Context, Event) error { return io.ErrClosedPipe }
+func (failingSink) Close() error                       { return nil }
+
+func TestRecorderLogsSinkErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	recorder := NewRecorder(failingSink{}, slog.New(slog.NewTextHandler(buf, nil)))
+
+	recorder.Record(context.Background(), Event{Operation: "deleteFile"})
+	require.Contains(t, buf.String(), "audit event not recorded")
+
+	var nilRecorder *Recorder
+	nilRecorder.Record(context.Background(), Event{})
+	require.NoError(t, nilRecorder.Close())
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+
+	"cloud.google.com/go/storage"
+)
+
+// NewSink returns the sink selected by cfg. client is only used by the gcs
+// sink.
+func NewSink(ctx context.Context, cfg Config, client *storage.Client) (Sink, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Sink {
+	case "file":
+		return NewFileSink(cfg.File)
+	case "gcs":
+		return NewGCSSink(ctx, client.Bucket(cfg.GCSBucket), cfg.GCSPrefix)
+	default:
+		return NewWriterSink(os.Stderr), nil
+	}
+}
+
+// WriterSink writes one JSON object per line, e.g. to stderr as a stream
+// separate from the application log on stdout.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(ctx context.Context, event Event) error {
+	line, err := encodeLine(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(line); err != nil {
+		return fmt.Errorf("writing audit event: %w", err)
+	}
+	return nil
+}
+
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// FileSink appends JSON lines to a file and syncs each event to disk before
+// the write returns.
+type FileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileSink opens path for appending, creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit file: %w", err)
+	}
+	return &FileSink{f: f}, nil
+}
+
+func (s *FileSink) Write(ctx context.Context, event Event) error {
+	line, err := encodeLine(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.f.Write(line); err != nil {
+		return fmt.Errorf("writing audit event: %w", err)
+	}
+	return s.f.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
+
+func encodeLine(event Event) ([]byte, error) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("encoding audit event: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+// GCSSink stores each event as its own object, named by time so a prefix
+// listing is in order. Objects are created with a does-not-exist
+// precondition and never overwritten, and the bucket's retention policy
+// keeps them from being deleted.
+type GCSSink struct {
+	bucket *storage.BucketHandle
+	prefix string
+	locked bool
+}
+
+// NewGCSSink requires the bucket to have a retention policy.
+func NewGCSSink(ctx context.Context, bucket *storage.BucketHandle, prefix string) (*GCSSink, error) {
+	attrs, err := bucket.Attrs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading audit bucket: %w", err)
+	}
+	if attrs.RetentionPolicy == nil || attrs.RetentionPolicy.RetentionPeriod <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoRetentionPolicy, attrs.Name)
+	}
+	return &GCSSink{bucket: bucket, prefix: prefix, locked: attrs.RetentionPolicy.IsLocked}, nil
+}
+
+// Locked reports whether the bucket's retention policy is locked. Until it
+// is, anyone allowed to update the bucket can remove the policy.
+func (s *GCSSink) Locked() bool {
+	return s.locked
+}
+
+func (s *GCSSink) Write(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding audit event: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := path.Join(s.prefix,
+		event.Time.UTC().Format("2006/01/02"),
+		fmt.Sprintf("%s-%s.json", event.Time.UTC().Format("150405.000000000"), hex.EncodeToString(suffix)),
+	)
+
+	// The request may have been canceled by the time it is audited.
+	w := s.bucket.Object(name).If(storage.Conditions{DoesNotExist: true}).NewWriter(context.WithoutCancel(ctx))
+	w.ContentType = "application/json"
+	if _, err := w.Write(body); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("writing audit object %s: %w", name, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("writing audit object %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *GCSSink) Close() error {
+	return nil
+}
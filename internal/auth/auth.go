@@ -2,19 +2,55 @@ package auth
 
 import (
 	"context"
+	"net/http"
 
+	"github.com/ogen-go/ogen/ogenerrors"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
 )
 
-// BasicAuthProvider implements SecuritySource interface
+// BasicAuthProvider implements SecuritySource interface.
+// Whichever of Username/Password, APIKey or Token is set is sent; unset schemes are skipped.
 type BasicAuthProvider struct {
 	Username string
 	Password string
+	APIKey   string
+	Token    string
 }
 
 func (b *BasicAuthProvider) BasicAuth(ctx context.Context, operationName fileupload.OperationName) (fileupload.BasicAuth, error) {
+	if b.Username == "" {
+		return fileupload.BasicAuth{}, ogenerrors.ErrSkipClientSecurity
+	}
+
 	return fileupload.BasicAuth{
 		Username: b.Username,
 		Password: b.Password,
 	}, nil
 }
+
+func (b *BasicAuthProvider) ApiKeyAuth(ctx context.Context, operationName fileupload.OperationName) (fileupload.ApiKeyAuth, error) {
+	if b.APIKey == "" {
+		return fileupload.ApiKeyAuth{}, ogenerrors.ErrSkipClientSecurity
+	}
+
+	return fileupload.ApiKeyAuth{
+		APIKey: b.APIKey,
+	}, nil
+}
+
+func (b *BasicAuthProvider) BearerAuth(ctx context.Context, operationName fileupload.OperationName) (fileupload.BearerAuth, error) {
+	if b.Token == "" {
+		return fileupload.BearerAuth{}, ogenerrors.ErrSkipClientSecurity
+	}
+
+	return fileupload.BearerAuth{
+		Token: b.Token,
+	}, nil
+}
+
+// MutualTLS adds nothing to the request; a client certificate is presented
+// by the TLS transport. It always succeeds so requests are sent and the
+// server decides whether the connection is authenticated.
+func (b *BasicAuthProvider) MutualTLS(ctx context.Context, operationName fileupload.OperationName, req *http.Request) error {
+	return nil
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted credentials file")
+
+// scrypt parameters recommended for interactive logins.
+const (
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	keyLength = 32
+)
+
+// encryptedFile is the on-disk form of an encrypted credentials file. The key
+// is derived from a passphrase with scrypt and the credentials are sealed with
+// AES-256-GCM, so the file can be moved between machines and systems.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptCredentials seals creds with a key derived from passphrase.
+func EncryptCredentials(creds Credentials, passphrase []byte) ([]byte, error) {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	file := encryptedFile{Version: 1, Salt: make([]byte, 16)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := credentialsCipher(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return nil, err
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plaintext, nil)
+
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// DecryptCredentials opens a file written by EncryptCredentials.
+func DecryptCredentials(data, passphrase []byte) (Credentials, error) {
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Credentials{}, fmt.Errorf("parsing credentials file: %w", err)
+	}
+	if file.Version != 1 {
+		return Credentials{}, fmt.Errorf("unsupported credentials file version %d", file.Version)
+	}
+
+	aead, err := credentialsCipher(passphrase, file.Salt)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return Credentials{}, ErrWrongPassphrase
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return Credentials{}, ErrWrongPassphrase
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("parsing credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// NewEncryptedFileSource reads credentials from a file written by
+// EncryptCredentials.
+func NewEncryptedFileSource(path string, passphrase []byte) *CredentialSource {
+	return NewCredentialSource(func(ctx context.Context) (Credentials, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("reading credentials file: %w", err)
+		}
+		return DecryptCredentials(data, passphrase)
+	})
+}
+
+func credentialsCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
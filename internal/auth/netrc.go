@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// NewNetrcSource reads the login and password for host from a netrc file,
+// falling back to its default entry.
+func NewNetrcSource(path, host string) *CredentialSource {
+	return NewCredentialSource(func(ctx context.Context) (Credentials, error) {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("reading netrc: %w", err)
+		}
+
+		creds, ok := parseNetrc(string(raw), host)
+		if !ok {
+			return Credentials{}, fmt.Errorf("%w: no netrc entry for %s", ErrNoCredentials, host)
+		}
+		return creds, nil
+	})
+}
+
+// parseNetrc returns the entry for host, or the default entry. Macros are
+// skipped.
+func parseNetrc(data, host string) (Credentials, bool) {
+	var (
+		found, fallback       Credentials
+		hasFound, hasFallback bool
+		current               *Credentials
+	)
+
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			if strings.HasPrefix(fields[j], "#") {
+				break
+			}
+
+			switch fields[j] {
+			case "machine":
+				current = nil
+				if j+1 < len(fields) {
+					j++
+					if fields[j] == host && !hasFound {
+						hasFound = true
+						current = &found
+					}
+				}
+			case "default":
+				current = nil
+				if !hasFallback {
+					hasFallback = true
+					current = &fallback
+				}
+			case "login", "password", "account":
+				if j+1 >= len(fields) {
+					continue
+				}
+				j++
+				if current == nil {
+					continue
+				}
+				switch fields[j-1] {
+				case "login":
+					current.Username = fields[j]
+				case "password":
+					current.Password = fields[j]
+				}
+			case "macdef":
+				// A macro runs until the next blank line.
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+
+	if hasFound {
+		return found, true
+	}
+	return fallback, hasFallback
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a named server in the client config file. Credentials are given
+// inline, by a netrc file or by a credential command.
+type Profile struct {
+	URL               string `yaml:"url"`
+	Credentials       `yaml:",inline"`
+	Netrc             string `yaml:"netrc"`
+	CredentialProcess string `yaml:"credentialProcess"`
+}
+
+type profilesFile struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultConfigPath is the client config file in the user's config directory,
+// e.g. ~/.config/file-upload/config.yaml on Linux.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "file-upload", "config.yaml")
+}
+
+// LoadProfile reads the named profile from a config file. An empty name
+// selects the file's default profile.
+func LoadProfile(path, name string) (Profile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var file profilesFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return Profile{}, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = file.Default
+	}
+	if name == "" {
+		name = "default"
+	}
+	profile, ok := file.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w: no profile %q in %s", ErrNoCredentials, name, path)
+	}
+	return profile, nil
+}
+
+// Source returns the profile's credential source; host selects the netrc
+// entry and defaults to the profile URL's host.
+func (p Profile) Source(host string) *CredentialSource {
+	switch {
+	case p.CredentialProcess != "":
+		return NewCommandSource(p.CredentialProcess)
+	case p.Netrc != "":
+		return NewNetrcSource(p.Netrc, host)
+	default:
+		creds := p.Credentials
+		return NewCredentialSource(func(ctx context.Context) (Credentials, error) {
+			return creds, nil
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+var ErrNoCredentials = errors.New("no credentials found")
+
+// Credentials are sent by the client. Whichever of Username/Password, APIKey
+// or Token is set is used.
+type Credentials struct {
+	Username string    `json:"username,omitempty" yaml:"username"`
+	Password string    `json:"password,omitempty" yaml:"password"`
+	APIKey   string    `json:"apiKey,omitempty" yaml:"apiKey"`
+	Token    string    `json:"token,omitempty" yaml:"token"`
+	Expires  time.Time `json:"expiration,omitzero" yaml:"-"`
+}
+
+func (c Credentials) empty() bool {
+	return c.Username == "" && c.APIKey == "" && c.Token == ""
+}
+
+// refreshBefore is how long before expiry cached credentials are reloaded.
+const refreshBefore = time.Minute
+
+// CredentialSource implements fileupload.SecuritySource by loading
+// credentials on first use and caching them until they expire.
+type CredentialSource struct {
+	load func(ctx context.Context) (Credentials, error)
+	now  func() time.Time
+
+	mu     sync.Mutex
+	cached *Credentials
+}
+
+var _ fileupload.SecuritySource = (*CredentialSource)(nil)
+
+// NewCredentialSource creates a source from a load function.
+func NewCredentialSource(load func(ctx context.Context) (Credentials, error)) *CredentialSource {
+	return &CredentialSource{load: load, now: time.Now}
+}
+
+// Credentials returns the cached credentials, loading them if there are none
+// or they are about to expire.
+func (s *CredentialSource) Credentials(ctx context.Context) (Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && (s.cached.Expires.IsZero() || s.now().Add(refreshBefore).Before(s.cached.Expires)) {
+		return *s.cached, nil
+	}
+
+	creds, err := s.load(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if creds.empty() {
+		return Credentials{}, ErrNoCredentials
+	}
+	s.cached = &creds
+	return creds, nil
+}
+
+func (s *CredentialSource) provider(ctx context.Context) (*BasicAuthProvider, error) {
+	creds, err := s.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &BasicAuthProvider{
+		Username: creds.Username,
+		Password: creds.Password,
+		APIKey:   creds.APIKey,
+		Token:    creds.Token,
+	}, nil
+}
+
+func (s *CredentialSource) BasicAuth(ctx context.Context, operationName fileupload.OperationName) (fileupload.BasicAuth, error) {
+	p, err := s.provider(ctx)
+	if err != nil {
+		return fileupload.BasicAuth{}, err
+	}
+	return p.BasicAuth(ctx, operationName)
+}
+
+func (s *CredentialSource) ApiKeyAuth(ctx context.Context, operationName fileupload.OperationName) (fileupload.ApiKeyAuth, error) {
+	p, err := s.provider(ctx)
+	if err != nil {
+		return fileupload.ApiKeyAuth{}, err
+	}
+	return p.ApiKeyAuth(ctx, operationName)
+}
+
+func (s *CredentialSource) BearerAuth(ctx context.Context, operationName fileupload.OperationName) (fileupload.BearerAuth, error) {
+	p, err := s.provider(ctx)
+	if err != nil {
+		return fileupload.BearerAuth{}, err
+	}
+	return p.BearerAuth(ctx, operationName)
+}
+
+func (s *CredentialSource) MutualTLS(ctx context.Context, operationName fileupload.OperationName, req *http.Request) error {
+	return nil
+}
+
+// NewCommandSource runs command, split on spaces, and reads credentials as
+// JSON from its stdout, e.g. {"apiKey": "...", "expiration": "2026-01-02T15:04:05Z"}.
+// The command is run again when the credentials expire.
+func NewCommandSource(command string) *CredentialSource {
+	return NewCredentialSource(func(ctx context.Context) (Credentials, error) {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			return Credentials{}, fmt.Errorf("%w: empty credential command", ErrNoCredentials)
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return Credentials{}, fmt.Errorf("running credential command: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+
+		var creds Credentials
+		if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+			return Credentials{}, fmt.Errorf("parsing credential command output: %w", err)
+		}
+		return creds, nil
+	})
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ogen-go/ogen/ogenerrors"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestParseNetrc(t *testing.T) {
+	data := `# comment
+machine other.example.com login other password secret
+machine upload.example.com
+  login partner-a
+  password s3cret
+macdef init
+  cd /tmp
+  machine upload.example.com login wrong password wrong
+
+default login anonymous password guest
+`
+	creds, ok := parseNetrc(data, "upload.example.com")
+	require.True(t, ok)
+	require.Equal(t, "partner-a", creds.Username)
+	require.Equal(t, "s3cret", creds.Password)
+
+	creds, ok = parseNetrc(data, "unknown.example.com")
+	require.True(t, ok)
+	require.Equal(t, "anonymous", creds.Username)
+
+	_, ok = parseNetrc("machine a login b password c", "unknown")
+	require.False(t, ok)
+}
+
+func TestNetrcSource(t *testing.T) {
+	path := writeFile(t, "netrc", "machine localhost login admin password pw\n")
+
+	basic, err := NewNetrcSource(path, "localhost").BasicAuth(context.Background(), fileupload.UploadFileOperation)
+	require.NoError(t, err)
+	require.Equal(t, "admin", basic.Username)
+	require.Equal(t, "pw", basic.Password)
+
+	_, err = NewNetrcSource(path, "elsewhere").BasicAuth(context.Background(), fileupload.UploadFileOperation)
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+default: prod
+profiles:
+  prod:
+    url: https://upload.example.com
+    apiKey: fuk_prod
+  staging:
+    url: https://staging.example.com
+    username: tester
+    password: pw
+`)
+
+	p, err := LoadProfile(path, "")
+	require.NoError(t, err)
+	require.Equal(t, "https://upload.example.com", p.URL)
+	key, err := p.Source("").ApiKeyAuth(context.Background(), fileupload.UploadFileOperation)
+	require.NoError(t, err)
+	require.Equal(t, "fuk_prod", key.APIKey)
+
+	// Schemes without credentials are skipped.
+	_, err = p.Source("").BasicAuth(context.Background(), fileupload.UploadFileOperation)
+	require.ErrorIs(t, err, ogenerrors.ErrSkipClientSecurity)
+
+	p, err = LoadProfile(path, "staging")
+	require.NoError(t, err)
+	require.Equal(t, "tester", p.Username)
+
+	_, err = LoadProfile(path, "missing")
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestEncryptedCredentials(t *testing.T) {
+	data, err := EncryptCredentials(Credentials{APIKey: "fuk_secret"}, []byte("passphrase"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "fuk_secret")
+
+	creds, err := DecryptCredentials(data, []byte("passphrase"))
+	require.NoError(t, err)
+	require.Equal(t, "fuk_secret", creds.APIKey)
+
+	_, err = DecryptCredentials(data, []byte("wrong"))
+	require.ErrorIs(t, err, ErrWrongPassphrase)
+
+	path := writeFile(t, "credentials.json", string(data))
+	key, err := NewEncryptedFileSource(path, []byte("passphrase")).ApiKeyAuth(context.Background(), fileupload.UploadFileOperation)
+	require.NoError(t, err)
+	require.Equal(t, "fuk_secret", key.APIKey)
+}
+
+func TestCommandSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	script := writeFile(t, "creds.sh", `#!/bin/sh
+echo '{"token": "abc", "expiration": "2999-01-01T00:00:00Z"}'
+`)
+	require.NoError(t, os.Chmod(script, 0o700))
+
+	bearer, err := NewCommandSource(script).BearerAuth(context.Background(), fileupload.UploadFileOperation)
+	require.NoError(t, err)
+	require.Equal(t, "abc", bearer.Token)
+
+	_, err = NewCommandSource("false").BearerAuth(context.Background(), fileupload.UploadFileOperation)
+	require.Error(t, err)
+}
+
+func TestCredentialSourceCachesUntilExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	loads := 0
+	s := NewCredentialSource(func(ctx context.Context) (Credentials, error) {
+		loads++
+		return Credentials{Token: "t", Expires: now.Add(10 * time.Minute)}, nil
+	})
+	s.now = func() time.Time { return now }
+
+	for range 3 {
+		_, err := s.Credentials(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, loads)
+
+	// Credentials are refreshed shortly before they expire.
+	now = now.Add(9*time.Minute + 30*time.Second)
+	_, err := s.Credentials(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, loads)
+}
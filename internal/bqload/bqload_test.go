@@ -0,0 +1,169 @@
+package bqload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/bqload/bqtest"
+)
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loads.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+loads:
+  - prefix: partner-a/
+    dataset: raw
+    table: partner_a
+    writeDisposition: WRITE_TRUNCATE
+    schema:
+      - {name: id, type: string, mode: required}
+      - {name: amount, type: NUMERIC}
+`), 0o600))
+
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+
+	schema, err := rules[0].schema()
+	require.NoError(t, err)
+	require.Equal(t, bigquery.StringFieldType, schema[0].Type)
+	require.True(t, schema[0].Required)
+
+	require.ErrorIs(t, Rule{Prefix: "a/", Dataset: "raw"}.validate(), ErrInvalidRule)
+	require.ErrorIs(t, Rule{Prefix: "a/", Dataset: "raw", Table: "t", WriteDisposition: "WRITE_SOMETIMES"}.validate(), ErrInvalidRule)
+	require.ErrorIs(t, Rule{Prefix: "a/", Dataset: "raw", Table: "t", Schema: []Field{{Name: "id", Type: "STRING", Mode: "OPTIONAL"}}}.validate(), ErrInvalidRule)
+}
+
+func TestMatchPrefersLongestPrefix(t *testing.T) {
+	rules := []Rule{
+		{Prefix: "", Table: "everything"},
+		{Prefix: "partner-a/", Table: "partner_a"},
+		{Prefix: "partner-a/refunds/", Table: "refunds"},
+	}
+
+	rule, ok := match(rules, "partner-a/refunds/2026.csv")
+	require.True(t, ok)
+	require.Equal(t, "refunds", rule.Table)
+
+	rule, ok = match(rules, "partner-b/x.csv")
+	require.True(t, ok)
+	require.Equal(t, "everything", rule.Table)
+
+	_, ok = match(rules[1:], "partner-b/x.csv")
+	require.False(t, ok)
+}
+
+func TestLoadRulesTreatsPrefixesAsFolders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loads.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+loads:
+  - {prefix: partner-a, dataset: raw, table: partner_a}
+`), 0o600))
+
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	require.Equal(t, "partner-a/", rules[0].Prefix)
+
+	_, ok := match(rules, "partner-a/x.csv")
+	require.True(t, ok)
+	_, ok = match(rules, "partner-ab/x.csv")
+	require.False(t, ok)
+}
+
+func newTestLoader(t *testing.T, rules []Rule) (*Loader, *bqtest.Server) {
+	return newEncryptedTestLoader(t, rules, nil)
+}
+
+func newEncryptedTestLoader(t *testing.T, rules []Rule, encrypted func(string) bool) (*Loader, *bqtest.Server) {
+	t.Helper()
+	srv := bqtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := srv.Client(context.Background(), "project")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return New(client, "bucket", rules, encrypted), srv
+}
+
+func TestStartLoadsMatchingCSV(t *testing.T) {
+	loader, fake := newTestLoader(t, []Rule{{
+		Prefix:           "partner-a/",
+		Dataset:          "raw",
+		Table:            "partner_a",
+		WriteDisposition: "WRITE_TRUNCATE",
+	}})
+	ctx := context.Background()
+
+	jobID, err := loader.Start(ctx, "partner-a/report.csv")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(jobID, jobIDPrefix))
+
+	job, ok := fake.Job(jobID)
+	require.True(t, ok)
+	load := job["configuration"].(map[string]any)["load"].(map[string]any)
+	require.Equal(t, []any{"gs://bucket/partner-a/report.csv"}, load["sourceUris"])
+	require.Equal(t, "WRITE_TRUNCATE", load["writeDisposition"])
+	require.Equal(t, "CSV", load["sourceFormat"])
+	require.Equal(t, true, load["autodetect"])
+	require.Equal(t, "partner_a", load["destinationTable"].(map[string]any)["tableId"])
+
+	status, err := loader.Status(ctx, jobID)
+	require.NoError(t, err)
+	require.Equal(t, "PENDING", status.State)
+	require.Equal(t, "partner-a/report.csv", status.Object)
+	require.Equal(t, "project.raw.partner_a", status.Table)
+}
+
+func TestStartSkipsUnmatchedAndNonCSV(t *testing.T) {
+	loader, fake := newTestLoader(t, []Rule{{Prefix: "partner-a/", Dataset: "raw", Table: "partner_a"}})
+
+	jobID, err := loader.Start(context.Background(), "partner-b/report.csv")
+	require.NoError(t, err)
+	require.Empty(t, jobID)
+
+	jobID, err = loader.Start(context.Background(), "partner-a/report.xlsx")
+	require.NoError(t, err)
+	require.Empty(t, jobID)
+	require.Zero(t, fake.Jobs())
+}
+
+func TestStartSkipsEncryptedObjects(t *testing.T) {
+	loader, fake := newEncryptedTestLoader(t, []Rule{{Prefix: "", Dataset: "raw", Table: "all"}}, func(object string) bool {
+		return strings.HasPrefix(object, "secret/")
+	})
+
+	_, err := loader.Start(context.Background(), "secret/report.csv")
+	require.ErrorIs(t, err, ErrEncrypted)
+	require.Zero(t, fake.Jobs())
+
+	jobID, err := loader.Start(context.Background(), "public/report.csv")
+	require.NoError(t, err)
+	require.NotEmpty(t, jobID)
+}
+
+func TestStatusUnknownJob(t *testing.T) {
+	loader, fake := newTestLoader(t, nil)
+
+	_, err := loader.Status(context.Background(), "upload_missing")
+	require.ErrorIs(t, err, ErrJobNotFound)
+
+	_, err = loader.Status(context.Background(), "someone_elses_job")
+	require.ErrorIs(t, err, ErrJobNotFound)
+
+	// A job with our prefix loading from another bucket is not ours.
+	fake.PutJob("upload_other_bucket", map[string]any{
+		"jobReference": map[string]any{"projectId": "project", "jobId": "upload_other_bucket"},
+		"configuration": map[string]any{"load": map[string]any{
+			"sourceUris":       []string{"gs://other-bucket/partner-a/report.csv"},
+			"destinationTable": map[string]any{"projectId": "project", "datasetId": "raw", "tableId": "t"},
+		}},
+		"status": map[string]any{"state": "DONE"},
+	})
+	_, err = loader.Status(context.Background(), "upload_other_bucket")
+	require.ErrorIs(t, err, ErrJobNotFound)
+}
@@ -0,0 +1,91 @@
+// Package bqtest provides an in-memory fake of the BigQuery jobs API, for
+// tests that need a real *bigquery.Client.
+package bqtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/option"
+)
+
+// Server stores inserted jobs as sent and serves them back as PENDING.
+type Server struct {
+	srv *httptest.Server
+
+	mu   sync.Mutex
+	jobs map[string]map[string]any
+}
+
+func NewServer() *Server {
+	s := &Server{jobs: map[string]map[string]any{}}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a BigQuery client for project talking to the fake.
+func (s *Server) Client(ctx context.Context, project string) (*bigquery.Client, error) {
+	return bigquery.NewClient(ctx, project,
+		option.WithEndpoint(s.srv.URL+"/bigquery/v2/"),
+		option.WithoutAuthentication(),
+	)
+}
+
+// Job returns the JSON resource of an inserted job.
+func (s *Server) Job(id string) (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+// Jobs returns the number of inserted jobs.
+func (s *Server) Jobs() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+// PutJob stores a job resource as if it had been inserted, e.g. one started
+// by someone else.
+func (s *Server) PutJob(id string, job map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[id] = job
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/jobs"):
+		var job map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		job["status"] = map[string]any{"state": "PENDING"}
+		ref, _ := job["jobReference"].(map[string]any)
+		id, _ := ref["jobId"].(string)
+		s.PutJob(id, job)
+		_ = json.NewEncoder(w).Encode(job)
+	case r.Method == http.MethodGet:
+		job, ok := s.Job(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Not found"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(job)
+	default:
+		http.Error(w, "not implemented by bqtest", http.StatusNotImplemented)
+	}
+}
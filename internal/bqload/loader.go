@@ -0,0 +1,185 @@
+package bqload
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/googleapi"
+)
+
+var (
+	// ErrJobNotFound is returned for ids that are not load jobs started by
+	// this service.
+	ErrJobNotFound = errors.New("load job not found")
+	// ErrEncrypted is returned for objects BigQuery cannot read because they
+	// are encrypted with keys only this service holds.
+	ErrEncrypted = errors.New("object is encrypted with a key bigquery cannot use")
+)
+
+// jobIDPrefix marks the jobs this service starts, so Status does not
+// report on other jobs in the project.
+const jobIDPrefix = "upload_"
+
+// Loader starts load jobs for uploads matching its rules.
+type Loader struct {
+	client    *bigquery.Client
+	bucket    string
+	rules     []Rule
+	encrypted func(object string) bool
+}
+
+// New returns a loader for objects in bucket that runs jobs with client, in
+// the client's project and location. encrypted reports objects stored with
+// CSEK or envelope encryption, which are not loaded; nil means none are.
+func New(client *bigquery.Client, bucket string, rules []Rule, encrypted func(object string) bool) *Loader {
+	return &Loader{client: client, bucket: bucket, rules: rules, encrypted: encrypted}
+}
+
+// Start starts a load job for the object and returns its id, or "" when no
+// rule matches or the file is not a CSV, which is the only uploaded format
+// BigQuery can load.
+func (l *Loader) Start(ctx context.Context, object string) (string, error) {
+	rule, ok := match(l.rules, object)
+	if !ok || !strings.EqualFold(path.Ext(object), ".csv") {
+		return "", nil
+	}
+	if l.encrypted != nil && l.encrypted(object) {
+		return "", fmt.Errorf("%w: %s", ErrEncrypted, object)
+	}
+
+	schema, err := rule.schema()
+	if err != nil {
+		return "", err
+	}
+
+	ref := bigquery.NewGCSReference(fmt.Sprintf("gs://%s/%s", l.bucket, object))
+	ref.SourceFormat = bigquery.CSV
+	ref.SkipLeadingRows = 1
+	if rule.SkipLeadingRows != nil {
+		ref.SkipLeadingRows = *rule.SkipLeadingRows
+	}
+	ref.Schema = schema
+	ref.AutoDetect = len(schema) == 0
+
+	dataset := l.client.Dataset(rule.Dataset)
+	if rule.Project != "" {
+		dataset = l.client.DatasetInProject(rule.Project, rule.Dataset)
+	}
+	loader := dataset.Table(rule.Table).LoaderFrom(ref)
+	loader.JobID = newJobID(time.Now())
+	loader.CreateDisposition = bigquery.CreateIfNeeded
+	loader.WriteDisposition = bigquery.WriteAppend
+	if rule.WriteDisposition != "" {
+		loader.WriteDisposition = bigquery.TableWriteDisposition(rule.WriteDisposition)
+	}
+	loader.Labels = map[string]string{"source": "file-upload"}
+
+	job, err := loader.Run(ctx)
+	if err != nil {
+		return "", fmt.Errorf("starting load job for %s: %w", object, err)
+	}
+	return job.ID(), nil
+}
+
+// Status describes a load job.
+type Status struct {
+	JobID string
+	// State is PENDING, RUNNING or DONE.
+	State  string
+	Object string
+	// Table is project.dataset.table.
+	Table      string
+	OutputRows int64
+	Errors     []string
+}
+
+// Status looks up a job started by Start. Jobs loading from another bucket
+// are reported as not found.
+func (l *Loader) Status(ctx context.Context, jobID string) (Status, error) {
+	if !strings.HasPrefix(jobID, jobIDPrefix) {
+		return Status{}, ErrJobNotFound
+	}
+
+	job, err := l.client.JobFromIDLocation(ctx, jobID, l.client.Location)
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return Status{}, ErrJobNotFound
+		}
+		return Status{}, fmt.Errorf("getting load job %s: %w", jobID, err)
+	}
+
+	config, err := job.Config()
+	if err != nil {
+		return Status{}, fmt.Errorf("reading load job %s: %w", jobID, err)
+	}
+	load, ok := config.(*bigquery.LoadConfig)
+	if !ok {
+		return Status{}, ErrJobNotFound
+	}
+	ref, ok := load.Src.(*bigquery.GCSReference)
+	if !ok || len(ref.URIs) != 1 {
+		return Status{}, ErrJobNotFound
+	}
+	bucket, object, ok := splitURI(ref.URIs[0])
+	if !ok || bucket != l.bucket {
+		return Status{}, ErrJobNotFound
+	}
+
+	status := Status{
+		JobID:  job.ID(),
+		Object: object,
+		Table:  fmt.Sprintf("%s.%s.%s", load.Dst.ProjectID, load.Dst.DatasetID, load.Dst.TableID),
+	}
+
+	last := job.LastStatus()
+	switch last.State {
+	case bigquery.Pending:
+		status.State = "PENDING"
+	case bigquery.Running:
+		status.State = "RUNNING"
+	default:
+		status.State = "DONE"
+	}
+	if last.Statistics != nil {
+		if stats, ok := last.Statistics.Details.(*bigquery.LoadStatistics); ok {
+			status.OutputRows = stats.OutputRows
+		}
+	}
+	// Err is the fatal error, usually repeated in Errors.
+	if err := last.Err(); err != nil {
+		status.Errors = append(status.Errors, err.Error())
+	}
+	for _, e := range last.Errors {
+		if e != nil && !slices.Contains(status.Errors, e.Error()) {
+			status.Errors = append(status.Errors, e.Error())
+		}
+	}
+	return status, nil
+}
+
+// splitURI splits gs://bucket/object.
+func splitURI(uri string) (bucket, object string, ok bool) {
+	rest, ok := strings.CutPrefix(uri, "gs://")
+	if !ok {
+		return "", "", false
+	}
+	bucket, object, ok = strings.Cut(rest, "/")
+	return bucket, object, ok && bucket != "" && object != ""
+}
+
+// newJobID starts with jobIDPrefix and the time the job was started.
+func newJobID(t time.Time) string {
+	suffix := make([]byte, 6)
+	_, _ = rand.Read(suffix)
+	return jobIDPrefix + t.UTC().Format("20060102T150405") + "_" + hex.EncodeToString(suffix)
+}
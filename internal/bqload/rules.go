@@ -0,0 +1,126 @@
+// Package bqload starts BigQuery load jobs for uploaded files that match a
+// configured prefix, and reports on them.
+package bqload
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidRule = errors.New("invalid bigquery load rule")
+
+// Rule loads CSV files under Prefix into Project.Dataset.Table.
+type Rule struct {
+	Prefix string `yaml:"prefix"`
+	// Project defaults to the client's project.
+	Project string `yaml:"project"`
+	Dataset string `yaml:"dataset"`
+	Table   string `yaml:"table"`
+	// WriteDisposition is WRITE_APPEND (default), WRITE_TRUNCATE or WRITE_EMPTY.
+	WriteDisposition string `yaml:"writeDisposition"`
+	// SkipLeadingRows skips header rows; 1 by default.
+	SkipLeadingRows *int64 `yaml:"skipLeadingRows"`
+	// Schema is detected from the file when empty.
+	Schema []Field `yaml:"schema"`
+}
+
+// Field is one column of a rule's schema.
+type Field struct {
+	Name string `yaml:"name"`
+	// Type is a BigQuery type such as STRING, INTEGER or TIMESTAMP.
+	Type string `yaml:"type"`
+	// Mode is NULLABLE (default), REQUIRED or REPEATED.
+	Mode        string `yaml:"mode"`
+	Description string `yaml:"description"`
+}
+
+type rulesFile struct {
+	Loads []Rule `yaml:"loads"`
+}
+
+// LoadRules reads and validates a rules file. Rule prefixes are folders, see
+// credentials.FolderPrefix.
+func LoadRules(path string) ([]Rule, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading bigquery load rules: %w", err)
+	}
+
+	var parsed rulesFile
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing bigquery load rules: %w", err)
+	}
+
+	for i, rule := range parsed.Loads {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		parsed.Loads[i].Prefix = credentials.FolderPrefix(rule.Prefix)
+	}
+	return parsed.Loads, nil
+}
+
+func (r Rule) validate() error {
+	if r.Dataset == "" || r.Table == "" {
+		return fmt.Errorf("%w: %q: dataset and table are required", ErrInvalidRule, r.Prefix)
+	}
+	switch bigquery.TableWriteDisposition(r.WriteDisposition) {
+	case "", bigquery.WriteAppend, bigquery.WriteTruncate, bigquery.WriteEmpty:
+	default:
+		return fmt.Errorf("%w: %q: unknown write disposition %q", ErrInvalidRule, r.Prefix, r.WriteDisposition)
+	}
+	if r.SkipLeadingRows != nil && *r.SkipLeadingRows < 0 {
+		return fmt.Errorf("%w: %q: skipLeadingRows must not be negative", ErrInvalidRule, r.Prefix)
+	}
+	if _, err := r.schema(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// schema converts Schema, or returns nil for autodetection.
+func (r Rule) schema() (bigquery.Schema, error) {
+	if len(r.Schema) == 0 {
+		return nil, nil
+	}
+	schema := make(bigquery.Schema, 0, len(r.Schema))
+	for _, f := range r.Schema {
+		if f.Name == "" || f.Type == "" {
+			return nil, fmt.Errorf("%w: %q: schema fields need a name and type", ErrInvalidRule, r.Prefix)
+		}
+		field := &bigquery.FieldSchema{
+			Name:        f.Name,
+			Type:        bigquery.FieldType(strings.ToUpper(f.Type)),
+			Description: f.Description,
+		}
+		switch strings.ToUpper(f.Mode) {
+		case "", "NULLABLE":
+		case "REQUIRED":
+			field.Required = true
+		case "REPEATED":
+			field.Repeated = true
+		default:
+			return nil, fmt.Errorf("%w: %q: field %s: unknown mode %q", ErrInvalidRule, r.Prefix, f.Name, f.Mode)
+		}
+		schema = append(schema, field)
+	}
+	return schema, nil
+}
+
+// match returns the rule with the longest prefix matching objectName.
+func match(rules []Rule, objectName string) (Rule, bool) {
+	var best Rule
+	found := false
+	for _, rule := range rules {
+		if strings.HasPrefix(objectName, rule.Prefix) && (!found || len(rule.Prefix) > len(best.Prefix)) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,167 @@
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidConfig = errors.New("invalid cors config")
+
+// Config is the CORS policy. An origin of "*" allows any origin, but cannot
+// be combined with AllowCredentials.
+type Config struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Policy applies a Config to HTTP responses.
+type Policy struct {
+	cfg         Config
+	anyOrigin   bool
+	origins     map[string]bool
+	methodList  []string
+	methods     string
+	headers     string
+	headerNames map[string]bool
+	exposed     string
+	maxAge      string
+}
+
+func New(cfg Config) (*Policy, error) {
+	p := &Policy{
+		cfg:         cfg,
+		origins:     map[string]bool{},
+		headerNames: map[string]bool{},
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.TrimSpace(origin)
+		switch origin {
+		case "":
+		case "*":
+			p.anyOrigin = true
+		default:
+			p.origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+		}
+	}
+	if p.anyOrigin && cfg.AllowCredentials {
+		return nil, fmt.Errorf("%w: wildcard origin cannot allow credentials", ErrInvalidConfig)
+	}
+
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+			methods = append(methods, method)
+		}
+	}
+	p.methodList = methods
+	p.methods = strings.Join(methods, ", ")
+
+	headers := make([]string, 0, len(cfg.AllowedHeaders))
+	for _, header := range cfg.AllowedHeaders {
+		if header = http.CanonicalHeaderKey(strings.TrimSpace(header)); header != "" {
+			headers = append(headers, header)
+			p.headerNames[header] = true
+		}
+	}
+	p.headers = strings.Join(headers, ", ")
+
+	exposed := slices.DeleteFunc(slices.Clone(cfg.ExposedHeaders), func(h string) bool {
+		return strings.TrimSpace(h) == ""
+	})
+	p.exposed = strings.Join(exposed, ", ")
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+	return p, nil
+}
+
+// Handler answers preflight requests and adds CORS headers to every other
+// response, including errors written by ogen.
+func (p *Policy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		allowed := p.allowOrigin(origin)
+		if !allowed {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if p.anyOrigin {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.cfg.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if p.exposed != "" {
+				header.Set("Access-Control-Expose-Headers", p.exposed)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !p.allowRequest(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		header.Set("Access-Control-Allow-Methods", p.methods)
+		if p.headers != "" {
+			header.Set("Access-Control-Allow-Headers", p.headers)
+		}
+		if p.maxAge != "" {
+			header.Set("Access-Control-Max-Age", p.maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (p *Policy) allowOrigin(origin string) bool {
+	return p.anyOrigin || p.origins[strings.ToLower(origin)]
+}
+
+// allowRequest checks the method and headers a preflight asks for.
+func (p *Policy) allowRequest(r *http.Request) bool {
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if !slices.Contains(p.methodList, method) {
+		return false
+	}
+
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = http.CanonicalHeaderKey(strings.TrimSpace(header)); header != "" && !p.headerNames[header] {
+			return false
+		}
+	}
+	return true
+}
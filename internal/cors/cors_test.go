@@ -0,0 +1,88 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, cfg Config, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	policy, err := New(cfg)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	policy.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})).ServeHTTP(rec, req)
+	return rec
+}
+
+func credentialedConfig() Config {
+	return Config{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+}
+
+func TestPreflightAllowedOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/upload", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+
+	rec := serve(t, credentialedConfig(), req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	require.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "Authorization, Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestPreflightRejectsUnknownOriginAndHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/upload", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec := serve(t, credentialedConfig(), req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	req = httptest.NewRequest(http.MethodOptions, "/upload", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "X-Custom")
+	rec = serve(t, credentialedConfig(), req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestSimpleRequestGetsHeadersOnErrors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+
+	rec := serve(t, credentialedConfig(), req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "Retry-After", rec.Header().Get("Access-Control-Expose-Headers"))
+	require.Equal(t, "Origin", rec.Header().Get("Vary"))
+}
+
+func TestWildcardOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	req.Header.Set("Origin", "https://anywhere.example.com")
+
+	rec := serve(t, Config{AllowedOrigins: []string{"*"}}, req)
+	require.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestNewRejectsWildcardWithCredentials(t *testing.T) {
+	_, err := New(Config{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
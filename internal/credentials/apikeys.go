@@ -0,0 +1,211 @@
+package credentials
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIKeyScheme is the leading marker of every issued key.
+const APIKeyScheme = "fuk"
+
+var (
+	ErrAPIKeyInvalid = errors.New("invalid api key")
+	ErrAPIKeyExpired = errors.New("api key expired")
+)
+
+// APIKey is the server-side record of an issued key. Only the SHA-256 hash of
+// the full key is stored; the prefix identifies the key in logs and lookups.
+type APIKey struct {
+	Name      string    `yaml:"name"`
+	Prefix    string    `yaml:"prefix"`
+	Hash      string    `yaml:"hash"`
+	Roles     []string  `yaml:"roles"`
+	ExpiresAt time.Time `yaml:"expiresAt,omitempty"`
+
+	// Grants is parsed from Roles when the key is loaded.
+	Grants Grants `yaml:"-"`
+}
+
+type apiKeysFile struct {
+	Keys []APIKey `yaml:"keys"`
+}
+
+// APIKeyStore holds API keys loaded from a YAML file and tracks when each key
+// was last used.
+type APIKeyStore struct {
+	path   string
+	logger *slog.Logger
+	now    func() time.Time
+
+	file fileState
+
+	mu       sync.RWMutex
+	keys     map[string]APIKey
+	lastUsed map[string]time.Time
+}
+
+// LoadAPIKeys reads API keys from a YAML file of the form
+// {keys: [{name, prefix, hash, roles, expiresAt}]}.
+func LoadAPIKeys(path string, logger *slog.Logger) (*APIKeyStore, error) {
+	s := &APIKeyStore{
+		path:     path,
+		logger:   logger,
+		now:      time.Now,
+		lastUsed: map[string]time.Time{},
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-reads the API key file. On error the previous keys are kept.
+func (s *APIKeyStore) Reload() error {
+	raw, info, err := readFile(s.path)
+	if err != nil {
+		return fmt.Errorf("reading api keys file: %w", err)
+	}
+
+	var parsed apiKeysFile
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	keys := make(map[string]APIKey, len(parsed.Keys))
+	for i, key := range parsed.Keys {
+		if key.Prefix == "" || key.Hash == "" {
+			return fmt.Errorf("%w: key %d needs a prefix and hash", ErrInvalidFile, i)
+		}
+		if _, ok := keys[key.Prefix]; ok {
+			return fmt.Errorf("%w: duplicate key prefix %q", ErrInvalidFile, key.Prefix)
+		}
+
+		roles := key.Roles
+		if len(roles) == 0 {
+			roles = DefaultRoles
+		}
+		grants, err := ParseGrants(roles)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key.Prefix, err)
+		}
+		key.Grants = grants
+		keys[key.Prefix] = key
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	s.file.record(info)
+
+	if s.logger != nil {
+		s.logger.Info("api keys loaded", "path", s.path, "keys", len(keys))
+	}
+	return nil
+}
+
+// Watch polls the API key file and reloads it when it changes, until ctx is cancelled.
+func (s *APIKeyStore) Watch(ctx context.Context, interval time.Duration) {
+	watchFile(ctx, s.path, interval, &s.file, s.Reload, s.logger)
+}
+
+// Len returns the number of loaded keys.
+func (s *APIKeyStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
+
+// Authenticate verifies a presented key and records its use.
+func (s *APIKeyStore) Authenticate(presented string) (APIKey, error) {
+	prefix, ok := APIKeyPrefix(presented)
+	if !ok {
+		return APIKey{}, ErrAPIKeyInvalid
+	}
+
+	s.mu.RLock()
+	key, ok := s.keys[prefix]
+	s.mu.RUnlock()
+
+	if !ok || !hashMatches(key.Hash, presented) {
+		return APIKey{}, ErrAPIKeyInvalid
+	}
+
+	now := s.now()
+	if !key.ExpiresAt.IsZero() && now.After(key.ExpiresAt) {
+		return APIKey{}, fmt.Errorf("%w: %s expired at %s", ErrAPIKeyExpired, prefix, key.ExpiresAt.Format(time.RFC3339))
+	}
+
+	s.mu.Lock()
+	s.lastUsed[prefix] = now
+	s.mu.Unlock()
+
+	return key, nil
+}
+
+// LastUsed returns when the key with the given prefix last authenticated
+// successfully since the process started.
+func (s *APIKeyStore) LastUsed(prefix string) (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.lastUsed[prefix]
+	return t, ok
+}
+
+// APIKeyPrefix extracts the identifying prefix from a key of the form
+// fuk_<prefix>_<secret>.
+func APIKeyPrefix(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != APIKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// GenerateAPIKey creates a new random key and the record to store for it.
+// The returned key is shown once and never stored.
+func GenerateAPIKey(name string, roles []string, expiresAt time.Time) (string, APIKey, error) {
+	if _, err := ParseGrants(roles); err != nil {
+		return "", APIKey{}, err
+	}
+
+	prefix := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", APIKey{}, fmt.Errorf("generating key prefix: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", APIKey{}, fmt.Errorf("generating key secret: %w", err)
+	}
+
+	key := fmt.Sprintf("%s_%s_%s", APIKeyScheme, hex.EncodeToString(prefix), base64.RawURLEncoding.EncodeToString(secret))
+	return key, APIKey{
+		Name:      name,
+		Prefix:    hex.EncodeToString(prefix),
+		Hash:      HashAPIKey(key),
+		Roles:     roles,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// HashAPIKey returns the hex SHA-256 of a key. Keys are high-entropy random
+// values, so a fast hash is sufficient.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashMatches(stored, presented string) bool {
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(HashAPIKey(presented))) == 1
+}
@@ -0,0 +1,78 @@
+package credentials
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func writeAPIKeys(t *testing.T, keys ...APIKey) string {
+	t.Helper()
+	raw, err := yaml.Marshal(apiKeysFile{Keys: keys})
+	require.NoError(t, err)
+	return writeFile(t, "keys.yaml", string(raw))
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	key, record, err := GenerateAPIKey("etl", []string{"upload:partner-a/"}, time.Time{})
+	require.NoError(t, err)
+	require.NotContains(t, record.Hash, key)
+
+	store, err := LoadAPIKeys(writeAPIKeys(t, record), newDiscardLogger())
+	require.NoError(t, err)
+
+	_, used := store.LastUsed(record.Prefix)
+	require.False(t, used)
+
+	got, err := store.Authenticate(key)
+	require.NoError(t, err)
+	require.Equal(t, "etl", got.Name)
+	require.True(t, got.Grants.Allows(PermissionUpload, "partner-a/x.csv"))
+
+	_, used = store.LastUsed(record.Prefix)
+	require.True(t, used)
+}
+
+func TestAPIKeyRejectsWrongSecret(t *testing.T) {
+	_, record, err := GenerateAPIKey("etl", nil, time.Time{})
+	require.NoError(t, err)
+
+	store, err := LoadAPIKeys(writeAPIKeys(t, record), newDiscardLogger())
+	require.NoError(t, err)
+
+	_, err = store.Authenticate(APIKeyScheme + "_" + record.Prefix + "_guessed")
+	require.ErrorIs(t, err, ErrAPIKeyInvalid)
+
+	_, err = store.Authenticate("not-a-key")
+	require.ErrorIs(t, err, ErrAPIKeyInvalid)
+}
+
+func TestAPIKeyRejectsExpiredKey(t *testing.T) {
+	key, record, err := GenerateAPIKey("etl", nil, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	store, err := LoadAPIKeys(writeAPIKeys(t, record), newDiscardLogger())
+	require.NoError(t, err)
+
+	_, err = store.Authenticate(key)
+	require.ErrorIs(t, err, ErrAPIKeyExpired)
+
+	_, used := store.LastUsed(record.Prefix)
+	require.False(t, used)
+}
+
+func TestLoadAPIKeysRejectsUnknownRole(t *testing.T) {
+	_, err := LoadAPIKeys(writeAPIKeys(t, APIKey{Prefix: "abcd", Hash: "00", Roles: []string{"root"}}), newDiscardLogger())
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestAPIKeyPrefix(t *testing.T) {
+	prefix, ok := APIKeyPrefix("fuk_ab12cd34_secret_with_underscores")
+	require.True(t, ok)
+	require.Equal(t, "ab12cd34", prefix)
+
+	_, ok = APIKeyPrefix("ghp_ab12cd34_secret")
+	require.False(t, ok)
+}
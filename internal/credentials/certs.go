@@ -0,0 +1,76 @@
+package credentials
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// ClientCertMapper maps verified client certificates onto principals.
+type ClientCertMapper struct {
+	identityGrants map[string]Grants
+}
+
+// NewClientCertMapper maps certificate identities to roles. When empty every
+// verified certificate gets DefaultRoles; otherwise only mapped identities
+// get roles.
+func NewClientCertMapper(identityRoles map[string][]string) (*ClientCertMapper, error) {
+	identityGrants := make(map[string]Grants, len(identityRoles))
+	for identity, roles := range identityRoles {
+		grants, err := ParseGrants(roles)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate %q: %w", identity, err)
+		}
+		identityGrants[identity] = grants
+	}
+	return &ClientCertMapper{identityGrants: identityGrants}, nil
+}
+
+// Identify returns the principal name and grants for a certificate. The name
+// is the first SAN (URI, DNS, then email) or subject common name that has a
+// role mapping, falling back to the first identity present.
+func (m *ClientCertMapper) Identify(cert *x509.Certificate) (string, Grants) {
+	identities := certIdentities(cert)
+	if len(identities) == 0 {
+		return "", nil
+	}
+
+	if len(m.identityGrants) == 0 {
+		grants, _ := ParseGrants(DefaultRoles)
+		return identities[0], grants
+	}
+
+	for _, identity := range identities {
+		if grants, ok := m.identityGrants[identity]; ok {
+			return identity, grants
+		}
+	}
+	return identities[0], nil
+}
+
+func certIdentities(cert *x509.Certificate) []string {
+	var identities []string
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	identities = append(identities, cert.DNSNames...)
+	identities = append(identities, cert.EmailAddresses...)
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	return identities
+}
+
+// LoadCertPool reads a PEM bundle of CA certificates.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading ca bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(raw) {
+		return nil, fmt.Errorf("%w: no certificates in %s", ErrInvalidFile, path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,57 @@
+package credentials
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifyUsesDefaultRolesWithoutMapping(t *testing.T) {
+	mapper, err := NewClientCertMapper(nil)
+	require.NoError(t, err)
+
+	name, grants := mapper.Identify(&x509.Certificate{
+		Subject:  pkix.Name{CommonName: "partner-a"},
+		DNSNames: []string{"partner-a.example.com"},
+	})
+	require.Equal(t, "partner-a.example.com", name)
+	require.True(t, grants.Allows(PermissionUpload, "any.csv"))
+}
+
+func TestIdentifyMatchesMappedIdentity(t *testing.T) {
+	mapper, err := NewClientCertMapper(map[string][]string{
+		"partner-a": {"upload:partner-a/"},
+	})
+	require.NoError(t, err)
+
+	spiffe, err := url.Parse("spiffe://example.com/partner-b")
+	require.NoError(t, err)
+
+	name, grants := mapper.Identify(&x509.Certificate{
+		Subject:  pkix.Name{CommonName: "partner-a"},
+		DNSNames: []string{"partner-a.example.com"},
+	})
+	require.Equal(t, "partner-a", name)
+	require.True(t, grants.Allows(PermissionUpload, "partner-a/a.csv"))
+	require.False(t, grants.Allows(PermissionUpload, "b.csv"))
+
+	name, grants = mapper.Identify(&x509.Certificate{URIs: []*url.URL{spiffe}})
+	require.Equal(t, "spiffe://example.com/partner-b", name)
+	require.Empty(t, grants)
+}
+
+func TestIdentifyWithoutIdentity(t *testing.T) {
+	mapper, err := NewClientCertMapper(nil)
+	require.NoError(t, err)
+
+	name, _ := mapper.Identify(&x509.Certificate{})
+	require.Empty(t, name)
+}
+
+func TestNewClientCertMapperRejectsUnknownRole(t *testing.T) {
+	_, err := NewClientCertMapper(map[string][]string{"partner-a": {"superuser"}})
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
@@ -0,0 +1,276 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidFile = errors.New("invalid credentials file")
+
+// User is a principal that authenticates with a username and password.
+type User struct {
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	Roles    []string `yaml:"roles"`
+
+	// Grants is parsed from Roles when the user is loaded.
+	Grants Grants `yaml:"-"`
+}
+
+type usersFile struct {
+	Users []User `yaml:"users"`
+}
+
+// Store holds users loaded from env or a credentials file and is safe for
+// concurrent use while being reloaded.
+type Store struct {
+	path   string
+	logger *slog.Logger
+
+	file fileState
+
+	mu       sync.RWMutex
+	users    map[string]User
+	fallback User
+}
+
+// NewSingleUser creates a store holding one admin user, used for the
+// AUTH_USERNAME/AUTH_PASSWORD pair. The password may be plaintext or bcrypt.
+func NewSingleUser(username, password string) *Store {
+	s := &Store{}
+	s.users = map[string]User{username: {
+		Username: username,
+		Password: password,
+		Roles:    []string{string(PermissionAdmin)},
+		Grants:   Grants{{Permission: PermissionAdmin}},
+	}}
+	s.fallback = fallbackFor(password)
+	return s
+}
+
+// NewStatic creates a store from fixed users. Passwords may be plaintext or bcrypt.
+func NewStatic(users ...User) (*Store, error) {
+	s := &Store{}
+	if err := s.set(users); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load reads users from an htpasswd file or, for .yaml/.yml files, a YAML
+// list of {username, password, roles}. File passwords must be bcrypt hashes.
+func Load(path string, logger *slog.Logger) (*Store, error) {
+	s := &Store{path: path, logger: logger}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-reads the credentials file. On error the previous users are kept.
+func (s *Store) Reload() error {
+	if s.path == "" {
+		return nil
+	}
+
+	raw, info, err := readFile(s.path)
+	if err != nil {
+		return fmt.Errorf("reading credentials file: %w", err)
+	}
+
+	var users []User
+	switch strings.ToLower(filepath.Ext(s.path)) {
+	case ".yaml", ".yml":
+		users, err = parseYAML(raw)
+	default:
+		users, err = parseHtpasswd(raw)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		if !isBcrypt(u.Password) {
+			return fmt.Errorf("%w: password for %q is not a bcrypt hash", ErrInvalidFile, u.Username)
+		}
+	}
+
+	if err := s.set(users); err != nil {
+		return err
+	}
+
+	s.file.record(info)
+
+	if s.logger != nil {
+		s.logger.Info("credentials loaded", "path", s.path, "users", len(users))
+	}
+	return nil
+}
+
+// Watch polls the credentials file and reloads it when its size or
+// modification time changes, until ctx is cancelled.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	watchFile(ctx, s.path, interval, &s.file, s.Reload, s.logger)
+}
+
+// Len returns the number of loaded users.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users)
+}
+
+// Authenticate returns the user when the password matches. Unknown usernames
+// are still checked against a fallback so timing does not reveal which exist.
+func (s *Store) Authenticate(username, password string) (User, bool) {
+	s.mu.RLock()
+	user, ok := s.users[username]
+	if !ok {
+		user = s.fallback
+	}
+	s.mu.RUnlock()
+
+	matches := passwordMatches(user.Password, password)
+	if !ok || !matches {
+		return User{}, false
+	}
+	return user, true
+}
+
+func (s *Store) set(users []User) error {
+	byName := make(map[string]User, len(users))
+	for _, u := range users {
+		roles := u.Roles
+		if len(roles) == 0 {
+			roles = DefaultRoles
+		}
+		grants, err := ParseGrants(roles)
+		if err != nil {
+			return fmt.Errorf("user %q: %w", u.Username, err)
+		}
+		u.Grants = grants
+		byName[u.Username] = u
+	}
+
+	var fallback User
+	if len(users) > 0 {
+		fallback = fallbackFor(users[0].Password)
+	}
+
+	s.mu.Lock()
+	s.users = byName
+	s.fallback = fallback
+	s.mu.Unlock()
+	return nil
+}
+
+// fallbackFor returns a user that never authenticates and whose password is
+// the same kind as sample, so the unknown-user path costs the same as the
+// known-user path.
+func fallbackFor(sample string) User {
+	const invalid = "\x00invalid"
+	if !isBcrypt(sample) {
+		return User{Password: invalid}
+	}
+	hash, _ := bcrypt.GenerateFromPassword([]byte(invalid), bcryptCost(sample))
+	return User{Password: string(hash)}
+}
+
+func parseYAML(raw []byte) ([]User, error) {
+	var parsed usersFile
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	seen := map[string]bool{}
+	for i, u := range parsed.Users {
+		if u.Username == "" {
+			return nil, fmt.Errorf("%w: user %d has no username", ErrInvalidFile, i)
+		}
+		if seen[u.Username] {
+			return nil, fmt.Errorf("%w: duplicate user %q", ErrInvalidFile, u.Username)
+		}
+		seen[u.Username] = true
+	}
+	return parsed.Users, nil
+}
+
+// parseHtpasswd reads "username:hash" lines. An optional third field holds
+// comma separated roles, e.g. "partner-a:$2y$10$...:upload".
+func parseHtpasswd(raw []byte) ([]User, error) {
+	var users []User
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(text, ":", 3)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%w: line %d is not username:hash", ErrInvalidFile, line)
+		}
+		if seen[parts[0]] {
+			return nil, fmt.Errorf("%w: duplicate user %q", ErrInvalidFile, parts[0])
+		}
+		seen[parts[0]] = true
+
+		user := User{Username: parts[0], Password: parts[1]}
+		if len(parts) == 3 {
+			for _, role := range strings.Split(parts[2], ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					user.Roles = append(user.Roles, role)
+				}
+			}
+		}
+		users = append(users, user)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	return users, nil
+}
+
+func passwordMatches(stored, password string) bool {
+	if isBcrypt(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+	return constantTimeEqual(password, stored)
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") ||
+		strings.HasPrefix(hash, "$2b$") ||
+		strings.HasPrefix(hash, "$2y$")
+}
+
+func bcryptCost(hash string) int {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return bcrypt.DefaultCost
+	}
+	return cost
+}
+
+func constantTimeEqual(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
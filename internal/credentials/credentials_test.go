@@ -0,0 +1,118 @@
+package credentials
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newDiscardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func hash(t *testing.T, password string) string {
+	t.Helper()
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	return string(h)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadHtpasswd(t *testing.T) {
+	path := writeFile(t, "users.htpasswd", "# partners\n"+
+		"partner-a:"+hash(t, "alpha")+":upload,list\n"+
+		"partner-b:"+hash(t, "bravo")+"\n")
+
+	store, err := Load(path, newDiscardLogger())
+	require.NoError(t, err)
+	require.Equal(t, 2, store.Len())
+
+	user, ok := store.Authenticate("partner-a", "alpha")
+	require.True(t, ok)
+	require.Equal(t, []string{"upload", "list"}, user.Roles)
+
+	_, ok = store.Authenticate("partner-a", "bravo")
+	require.False(t, ok)
+
+	_, ok = store.Authenticate("partner-b", "bravo")
+	require.True(t, ok)
+}
+
+func TestLoadYAML(t *testing.T) {
+	path := writeFile(t, "users.yaml", `
+users:
+  - username: partner-a
+    password: "`+hash(t, "alpha")+`"
+    roles: [upload]
+`)
+
+	store, err := Load(path, newDiscardLogger())
+	require.NoError(t, err)
+
+	user, ok := store.Authenticate("partner-a", "alpha")
+	require.True(t, ok)
+	require.Equal(t, []string{"upload"}, user.Roles)
+}
+
+func TestLoadRejectsPlaintextPasswords(t *testing.T) {
+	_, err := Load(writeFile(t, "users.htpasswd", "admin:password\n"), newDiscardLogger())
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestLoadRejectsDuplicateUsers(t *testing.T) {
+	h := hash(t, "x")
+	_, err := Load(writeFile(t, "users.htpasswd", "a:"+h+"\na:"+h+"\n"), newDiscardLogger())
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestAuthenticateUnknownUser(t *testing.T) {
+	store, err := NewStatic(User{Username: "admin", Password: "password"})
+	require.NoError(t, err)
+
+	_, ok := store.Authenticate("nobody", "password")
+	require.False(t, ok)
+
+	_, ok = store.Authenticate("admin", "password")
+	require.True(t, ok)
+}
+
+func TestReloadKeepsPreviousUsersOnError(t *testing.T) {
+	path := writeFile(t, "users.htpasswd", "a:"+hash(t, "old")+"\n")
+	store, err := Load(path, newDiscardLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("a:not-a-hash\n"), 0o600))
+	require.Error(t, store.Reload())
+
+	_, ok := store.Authenticate("a", "old")
+	require.True(t, ok)
+}
+
+func TestWatchReloadsChangedFile(t *testing.T) {
+	path := writeFile(t, "users.htpasswd", "a:"+hash(t, "old")+"\n")
+	store, err := Load(path, newDiscardLogger())
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	go store.Watch(ctx, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(path, []byte("a:"+hash(t, "new")+"\nb:"+hash(t, "b")+"\n"), 0o600))
+
+	require.Eventually(t, func() bool {
+		_, ok := store.Authenticate("a", "new")
+		return ok
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, 2, store.Len())
+}
@@ -0,0 +1,337 @@
+package credentials
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrTokenInvalid  = errors.New("invalid bearer token")
+	ErrInvalidConfig = errors.New("invalid auth config")
+)
+
+const (
+	defaultJWKSRefreshInterval = 15 * time.Minute
+	// minJWKSRefetch bounds how often an unknown kid can trigger a fetch.
+	minJWKSRefetch = 30 * time.Second
+	// jwksFetchTimeout bounds a fetch, which no request can cancel.
+	jwksFetchTimeout = 10 * time.Second
+)
+
+// JWTConfig configures verification of OIDC ID tokens.
+type JWTConfig struct {
+	JWKSURL   string
+	Issuer    string
+	Audiences []string
+
+	// EmailClaim and GroupsClaim name the claims mapped onto the principal.
+	EmailClaim  string
+	GroupsClaim string
+
+	// GroupRoles maps a group to roles. When empty every valid token gets
+	// DefaultRoles; otherwise tokens only get the roles of their groups.
+	GroupRoles map[string][]string
+
+	RefreshInterval time.Duration
+	Leeway          time.Duration
+	HTTPClient      *http.Client
+}
+
+// TokenClaims is the identity extracted from a verified token.
+type TokenClaims struct {
+	Subject string
+	Email   string
+	Groups  []string
+	Grants  Grants
+}
+
+// JWTVerifier validates bearer tokens against a cached JWKS.
+type JWTVerifier struct {
+	cfg         JWTConfig
+	parser      *jwt.Parser
+	groupGrants map[string]Grants
+	jwks        *jwksCache
+}
+
+// NewJWTVerifier validates the config. Keys are fetched lazily on first use.
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	if cfg.JWKSURL == "" || cfg.Issuer == "" || len(cfg.Audiences) == 0 {
+		return nil, fmt.Errorf("%w: jwks url, issuer and audience are required", ErrInvalidConfig)
+	}
+	if cfg.EmailClaim == "" {
+		cfg.EmailClaim = "email"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultJWKSRefreshInterval
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	groupGrants := make(map[string]Grants, len(cfg.GroupRoles))
+	for group, roles := range cfg.GroupRoles {
+		grants, err := ParseGrants(roles)
+		if err != nil {
+			return nil, fmt.Errorf("group %q: %w", group, err)
+		}
+		groupGrants[group] = grants
+	}
+
+	return &JWTVerifier{
+		cfg: cfg,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audiences...),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(cfg.Leeway),
+		),
+		groupGrants: groupGrants,
+		jwks: &jwksCache{
+			url:     cfg.JWKSURL,
+			client:  cfg.HTTPClient,
+			refresh: cfg.RefreshInterval,
+		},
+	}, nil
+}
+
+// Verify checks the token's signature, issuer, audience and expiry and maps
+// its claims onto an identity.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (TokenClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.jwks.key(ctx, kid)
+	})
+	if err != nil {
+		return TokenClaims{}, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	email, _ := claims[v.cfg.EmailClaim].(string)
+	groups := stringList(claims[v.cfg.GroupsClaim])
+
+	return TokenClaims{
+		Subject: subject,
+		Email:   email,
+		Groups:  groups,
+		Grants:  v.grantsFor(groups),
+	}, nil
+}
+
+func (v *JWTVerifier) grantsFor(groups []string) Grants {
+	if len(v.groupGrants) == 0 {
+		grants, _ := ParseGrants(DefaultRoles)
+		return grants
+	}
+
+	var grants Grants
+	for _, group := range groups {
+		grants = append(grants, v.groupGrants[group]...)
+	}
+	return grants
+}
+
+func stringList(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		out := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// jwksCache keeps the issuer's signing keys and refetches them when they
+// expire or an unknown key id is seen, so key rotation needs no restart.
+// Fetches run in the background, outside mu and detached from the request
+// that triggered them, and concurrent requests share a single fetch.
+type jwksCache struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+	// fetching is closed when the fetch in progress, if any, finishes.
+	fetching chan struct{}
+}
+
+func (c *jwksCache) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	_, known := c.keys[kid]
+	stale := time.Since(c.fetchedAt) > c.refresh
+	if (stale || !known) && c.fetching == nil && time.Since(c.lastAttempt) > minJWKSRefetch {
+		c.lastAttempt = time.Now()
+		c.fetching = make(chan struct{})
+		go c.update(c.fetching)
+	}
+	fetching := c.fetching
+	c.mu.Unlock()
+
+	// Known keys are used straight away while a stale set is refreshed.
+	if !known && fetching != nil {
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	// Tokens without a kid are accepted when the issuer has a single key.
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, nil
+		}
+	}
+	if c.keys == nil && c.lastErr != nil {
+		return nil, c.lastErr
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// update fetches the keys and closes done. On failure the previous keys, if
+// any, stay in use.
+func (c *jwksCache) update(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	keys, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	if err == nil {
+		c.keys = keys
+		c.fetchedAt = time.Now()
+	}
+	c.lastErr = err
+	c.fetching = nil
+	c.mu.Unlock()
+	close(done)
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (c *jwksCache) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building jwks request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decoding jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,172 @@
+package credentials
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "https://issuer.example.com"
+
+func newJWKSServer(t *testing.T, kid string, key *rsa.PrivateKey) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwkSet{Keys: []jwk{{
+			Kid: kid,
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func signToken(t *testing.T, kid string, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newTestVerifier(t *testing.T, groupRoles map[string][]string) (*JWTVerifier, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := newJWKSServer(t, "k1", key)
+	verifier, err := NewJWTVerifier(JWTConfig{
+		JWKSURL:    server.URL,
+		Issuer:     testIssuer,
+		Audiences:  []string{"file-upload"},
+		GroupRoles: groupRoles,
+	})
+	require.NoError(t, err)
+	return verifier, key
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":    testIssuer,
+		"aud":    "file-upload",
+		"sub":    "user-1",
+		"email":  "user@example.com",
+		"groups": []string{"partners"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyValidToken(t *testing.T) {
+	verifier, key := newTestVerifier(t, nil)
+
+	claims, err := verifier.Verify(context.Background(), signToken(t, "k1", key, validClaims()))
+	require.NoError(t, err)
+	require.Equal(t, "user-1", claims.Subject)
+	require.Equal(t, "user@example.com", claims.Email)
+	require.Equal(t, []string{"partners"}, claims.Groups)
+	require.True(t, claims.Grants.Allows(PermissionUpload, "any/file.csv"))
+}
+
+func TestVerifyRejectsWrongAudience(t *testing.T) {
+	verifier, key := newTestVerifier(t, nil)
+
+	claims := validClaims()
+	claims["aud"] = "another-service"
+	_, err := verifier.Verify(context.Background(), signToken(t, "k1", key, claims))
+	require.ErrorIs(t, err, ErrTokenInvalid)
+}
+
+func TestVerifyRejectsExpiredToken(t *testing.T) {
+	verifier, key := newTestVerifier(t, nil)
+
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err := verifier.Verify(context.Background(), signToken(t, "k1", key, claims))
+	require.ErrorIs(t, err, ErrTokenInvalid)
+}
+
+func TestVerifyRejectsUnknownSigningKey(t *testing.T) {
+	verifier, _ := newTestVerifier(t, nil)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), signToken(t, "k1", other, validClaims()))
+	require.ErrorIs(t, err, ErrTokenInvalid)
+}
+
+func TestVerifyMapsGroupsToRoles(t *testing.T) {
+	verifier, key := newTestVerifier(t, map[string][]string{
+		"partners": {"upload:partners/", "list:partners/"},
+		"ops":      {"admin"},
+	})
+
+	claims, err := verifier.Verify(context.Background(), signToken(t, "k1", key, validClaims()))
+	require.NoError(t, err)
+	require.True(t, claims.Grants.Allows(PermissionUpload, "partners/a.csv"))
+	require.False(t, claims.Grants.Allows(PermissionUpload, "internal/a.csv"))
+
+	noGroups := validClaims()
+	delete(noGroups, "groups")
+	claims, err = verifier.Verify(context.Background(), signToken(t, "k1", key, noGroups))
+	require.NoError(t, err)
+	require.False(t, claims.Grants.AllowsAny(PermissionUpload))
+}
+
+func TestJWKSFetchIsSharedAndOutlivesRequest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks := newJWKSServer(t, "k1", key)
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		jwks.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	verifier, err := NewJWTVerifier(JWTConfig{JWKSURL: slow.URL, Issuer: testIssuer, Audiences: []string{"file-upload"}})
+	require.NoError(t, err)
+	token := signToken(t, "k1", key, validClaims())
+
+	// A request that gives up does not cancel the fetch or hold up others.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = verifier.Verify(ctx, token)
+	require.ErrorIs(t, err, ErrTokenInvalid)
+
+	results := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, err := verifier.Verify(context.Background(), token)
+			results <- err
+		}()
+	}
+	release <- struct{}{}
+	for range 3 {
+		require.NoError(t, <-results)
+	}
+	require.EqualValues(t, 1, fetches.Load())
+}
+
+func TestNewJWTVerifierRequiresIssuerAndAudience(t *testing.T) {
+	_, err := NewJWTVerifier(JWTConfig{JWKSURL: "https://issuer.example.com/jwks"})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
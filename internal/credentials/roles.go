@@ -0,0 +1,99 @@
+package credentials
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Permission is an action a principal may be granted.
+type Permission string
+
+const (
+	PermissionUpload Permission = "upload"
+	PermissionRead   Permission = "read"
+	PermissionList   Permission = "list"
+	PermissionDelete Permission = "delete"
+	// PermissionAdmin implies every other permission.
+	PermissionAdmin Permission = "admin"
+)
+
+var permissions = []Permission{
+	PermissionUpload,
+	PermissionRead,
+	PermissionList,
+	PermissionDelete,
+	PermissionAdmin,
+}
+
+// DefaultRoles apply to users configured without roles, preserving the
+// upload-only access that existed before roles were introduced.
+var DefaultRoles = []string{string(PermissionUpload)}
+
+// Grant allows a permission on objects whose name starts with Prefix, which
+// ends in "/". An empty prefix covers the whole bucket.
+type Grant struct {
+	Permission Permission
+	Prefix     string
+}
+
+// Grants is the set of permissions held by a principal.
+type Grants []Grant
+
+// ParseGrants parses roles of the form "permission" or "permission:prefix",
+// e.g. "upload:partner-a/". Prefixes are folders, see FolderPrefix.
+func ParseGrants(roles []string) (Grants, error) {
+	grants := make(Grants, 0, len(roles))
+	for _, role := range roles {
+		name, prefix, _ := strings.Cut(strings.TrimSpace(role), ":")
+		permission := Permission(strings.ToLower(name))
+		if !slices.Contains(permissions, permission) {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidFile, role)
+		}
+		grants = append(grants, Grant{Permission: permission, Prefix: FolderPrefix(prefix)})
+	}
+	return grants, nil
+}
+
+// FolderPrefix adds the trailing slash a non-empty object prefix is missing,
+// so that "partner-a" matches "partner-a/..." but not "partner-ab/...".
+func FolderPrefix(prefix string) string {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		return prefix + "/"
+	}
+	return prefix
+}
+
+// Allows reports whether the grants cover the permission on the object key.
+func (g Grants) Allows(permission Permission, key string) bool {
+	for _, grant := range g {
+		if grant.Permission != permission && grant.Permission != PermissionAdmin {
+			continue
+		}
+		if strings.HasPrefix(key, grant.Prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAny reports whether the permission is granted under at least one prefix.
+func (g Grants) AllowsAny(permission Permission) bool {
+	for _, grant := range g {
+		if grant.Permission == permission || grant.Permission == PermissionAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Prefixes returns the prefixes under which the permission is granted.
+func (g Grants) Prefixes(permission Permission) []string {
+	var prefixes []string
+	for _, grant := range g {
+		if grant.Permission == permission || grant.Permission == PermissionAdmin {
+			prefixes = append(prefixes, grant.Prefix)
+		}
+	}
+	return prefixes
+}
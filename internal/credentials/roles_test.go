@@ -0,0 +1,58 @@
+package credentials
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGrants(t *testing.T) {
+	grants, err := ParseGrants([]string{"upload:partner-a/", "LIST", "read:partner-a/"})
+	require.NoError(t, err)
+	require.Equal(t, Grants{
+		{Permission: PermissionUpload, Prefix: "partner-a/"},
+		{Permission: PermissionList},
+		{Permission: PermissionRead, Prefix: "partner-a/"},
+	}, grants)
+
+	_, err = ParseGrants([]string{"superuser"})
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestParseGrantsScopesPrefixToFolder(t *testing.T) {
+	grants, err := ParseGrants([]string{"upload:partner-a"})
+	require.NoError(t, err)
+	require.Equal(t, Grants{{Permission: PermissionUpload, Prefix: "partner-a/"}}, grants)
+
+	require.True(t, grants.Allows(PermissionUpload, "partner-a/report.csv"))
+	require.False(t, grants.Allows(PermissionUpload, "partner-ab/report.csv"))
+}
+
+func TestGrantsAllowsRespectsPrefix(t *testing.T) {
+	grants := Grants{{Permission: PermissionUpload, Prefix: "partner-a/"}}
+
+	require.True(t, grants.Allows(PermissionUpload, "partner-a/report.csv"))
+	require.False(t, grants.Allows(PermissionUpload, "partner-b/report.csv"))
+	require.False(t, grants.Allows(PermissionUpload, "report.csv"))
+	require.False(t, grants.Allows(PermissionDelete, "partner-a/report.csv"))
+
+	require.True(t, grants.AllowsAny(PermissionUpload))
+	require.False(t, grants.AllowsAny(PermissionRead))
+}
+
+func TestAdminGrantImpliesEveryPermission(t *testing.T) {
+	grants := Grants{{Permission: PermissionAdmin}}
+
+	require.True(t, grants.Allows(PermissionDelete, "anything.csv"))
+	require.True(t, grants.AllowsAny(PermissionList))
+}
+
+func TestUsersWithoutRolesGetDefaultRoles(t *testing.T) {
+	store, err := NewStatic(User{Username: "a", Password: "p"})
+	require.NoError(t, err)
+
+	user, ok := store.Authenticate("a", "p")
+	require.True(t, ok)
+	require.True(t, user.Grants.Allows(PermissionUpload, "x.csv"))
+	require.False(t, user.Grants.AllowsAny(PermissionDelete))
+}
@@ -0,0 +1,78 @@
+package credentials
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileState remembers the size and modification time of the last load.
+type fileState struct {
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+func (f *fileState) record(info os.FileInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+}
+
+func (f *fileState) changed(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !info.ModTime().Equal(f.modTime) || info.Size() != f.size
+}
+
+func readFile(path string) ([]byte, os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return raw, info, nil
+}
+
+// watchFile calls reload whenever the file changes, until ctx is cancelled.
+// Failed reloads are logged and retried on the next change.
+func watchFile(ctx context.Context, path string, interval time.Duration, state *fileState, reload func() error, logger *slog.Logger) {
+	if path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !state.changed(path) {
+				continue
+			}
+			if err := reload(); err != nil {
+				// Remember the broken version so it is reported once, not every tick.
+				if info, statErr := os.Stat(path); statErr == nil {
+					state.record(info)
+				}
+				if logger != nil {
+					logger.Error("credentials reload failed; keeping previous entries", "path", path, "error", err)
+				}
+			}
+		}
+	}
+}
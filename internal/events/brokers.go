@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+)
+
+// PubSubPublisher publishes to a Google Pub/Sub topic.
+type PubSubPublisher struct {
+	client *pubsub.Client
+	topic  *pubsub.Topic
+}
+
+func NewPubSubPublisher(ctx context.Context, project, topic string) (*PubSubPublisher, error) {
+	client, err := pubsub.NewClient(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("creating pubsub client: %w", err)
+	}
+	return newPubSubPublisher(client, topic), nil
+}
+
+func newPubSubPublisher(client *pubsub.Client, topic string) *PubSubPublisher {
+	return &PubSubPublisher{client: client, topic: client.Topic(topic)}
+}
+
+func (p *PubSubPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	res := p.topic.Publish(ctx, &pubsub.Message{
+		Data:       data,
+		Attributes: map[string]string{"content-type": ContentType},
+	})
+	if _, err := res.Get(ctx); err != nil {
+		return fmt.Errorf("publishing to pubsub: %w", err)
+	}
+	return nil
+}
+
+func (p *PubSubPublisher) Close() error {
+	p.topic.Stop()
+	return p.client.Close()
+}
+
+// NATSPublisher publishes to a NATS JetStream stream and waits for the
+// stream to acknowledge the event. A stream must capture the subject;
+// without one the publish fails and the event stays in the outbox.
+type NATSPublisher struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	subject string
+}
+
+// NewNATSPublisher keeps retrying the connection in the background, so the
+// server starts while NATS is down and the outbox holds events until it is up.
+func NewNATSPublisher(url, subject string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("file-upload"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("creating jetstream context: %w", err)
+	}
+	return &NATSPublisher{conn: conn, js: js, subject: subject}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	msg := nats.NewMsg(p.subject)
+	msg.Header.Set("Content-Type", ContentType)
+	msg.Data = data
+	// The message id lets the stream drop a retry of an event it already
+	// stored within its duplicate window.
+	if _, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(event.ID)); err != nil {
+		return fmt.Errorf("publishing to nats: %w", err)
+	}
+	return nil
+}
+
+func (p *NATSPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
+
+// KafkaPublisher writes to a Kafka topic, keyed by the object name so events
+// for one object stay in order, and waits for all in-sync replicas.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// The outbox sends one event at a time; don't wait to fill a batch.
+		BatchSize: 1,
+	}}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	err = p.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(event.Subject),
+		Value:   data,
+		Headers: []kafka.Header{{Key: "content-type", Value: []byte(ContentType)}},
+	})
+	if err != nil {
+		return fmt.Errorf("publishing to kafka: %w", err)
+	}
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
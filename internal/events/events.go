@@ -0,0 +1,121 @@
+// Package events publishes upload events to a message broker as
+// CloudEvents, through an outbox so they survive broker outages.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/spool"
+)
+
+var ErrUnknownPublisher = errors.New("unknown event publisher")
+
+const (
+	// TypeUploadCompleted is the CloudEvents type of a successful upload.
+	TypeUploadCompleted = "com.totalprocessing.fileupload.upload.completed"
+	// ContentType marks the payload as a structured-mode CloudEvent.
+	ContentType = "application/cloudevents+json"
+)
+
+// Event is a CloudEvents 1.0 event in the structured JSON format.
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// UploadCompleted is the data of a TypeUploadCompleted event.
+type UploadCompleted struct {
+	Uploader string                     `json:"uploader"`
+	Upload   *fileupload.UploadResponse `json:"upload"`
+}
+
+// NewUploadCompleted returns the event for an upload, with the object name
+// as its subject.
+func NewUploadCompleted(source, uploader string, upload fileupload.UploadResponse) (Event, error) {
+	data, err := json.Marshal(UploadCompleted{Uploader: uploader, Upload: &upload})
+	if err != nil {
+		return Event{}, fmt.Errorf("encoding upload event: %w", err)
+	}
+
+	now := time.Now().UTC()
+	return Event{
+		SpecVersion:     "1.0",
+		ID:              spool.NewID(now),
+		Source:          source,
+		Type:            TypeUploadCompleted,
+		Subject:         upload.Filename,
+		Time:            now,
+		DataContentType: "application/json",
+		Data:            data,
+	}, nil
+}
+
+// Publisher sends events to a broker. Publish returns once the broker has
+// accepted the event.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// Config selects a publisher for NewPublisher.
+type Config struct {
+	// Publisher is pubsub, nats or kafka.
+	Publisher string
+
+	// PubSub publishes to PubSubTopic in PubSubProject. The client uses the
+	// emulator when PUBSUB_EMULATOR_HOST is set.
+	PubSubProject string
+	PubSubTopic   string
+
+	NATSURL     string
+	NATSSubject string
+
+	KafkaBrokers []string
+	KafkaTopic   string
+}
+
+func (c Config) validate() error {
+	switch c.Publisher {
+	case "pubsub":
+		if c.PubSubProject == "" || c.PubSubTopic == "" {
+			return errors.New("pubsub publisher requires a project and topic")
+		}
+	case "nats":
+		if c.NATSURL == "" || c.NATSSubject == "" {
+			return errors.New("nats publisher requires a url and subject")
+		}
+	case "kafka":
+		if len(c.KafkaBrokers) == 0 || c.KafkaTopic == "" {
+			return errors.New("kafka publisher requires brokers and a topic")
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownPublisher, c.Publisher)
+	}
+	return nil
+}
+
+// NewPublisher connects to the broker selected by cfg.
+func NewPublisher(ctx context.Context, cfg Config) (Publisher, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Publisher {
+	case "pubsub":
+		return NewPubSubPublisher(ctx, cfg.PubSubProject, cfg.PubSubTopic)
+	case "nats":
+		return NewNATSPublisher(cfg.NATSURL, cfg.NATSSubject)
+	default:
+		return NewKafkaPublisher(cfg.KafkaBrokers, cfg.KafkaTopic), nil
+	}
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestNewUploadCompletedIsCloudEvent(t *testing.T) {
+	event, err := NewUploadCompleted("/file-upload", "partner-a", fileupload.UploadResponse{
+		Filename: "partner-a/report.csv",
+		FileSize: 2048,
+	})
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.Equal(t, "1.0", decoded["specversion"])
+	require.Equal(t, TypeUploadCompleted, decoded["type"])
+	require.Equal(t, "/file-upload", decoded["source"])
+	require.Equal(t, "partner-a/report.csv", decoded["subject"])
+	require.Equal(t, "application/json", decoded["datacontenttype"])
+	require.NotEmpty(t, decoded["id"])
+
+	data := decoded["data"].(map[string]any)
+	require.Equal(t, "partner-a", data["uploader"])
+	require.Equal(t, "partner-a/report.csv", data["upload"].(map[string]any)["filename"])
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.ErrorIs(t, Config{Publisher: "sqs"}.validate(), ErrUnknownPublisher)
+	require.Error(t, Config{Publisher: "pubsub", PubSubProject: "p"}.validate())
+	require.Error(t, Config{Publisher: "kafka", KafkaTopic: "uploads"}.validate())
+	require.NoError(t, Config{Publisher: "nats", NATSURL: "nats://localhost:4222", NATSSubject: "uploads"}.validate())
+}
+
+func TestPubSubPublisher(t *testing.T) {
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	defer srv.Close()
+
+	conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	client, err := pubsub.NewClient(ctx, "project", option.WithGRPCConn(conn))
+	require.NoError(t, err)
+	_, err = client.CreateTopic(ctx, "uploads")
+	require.NoError(t, err)
+
+	publisher := newPubSubPublisher(client, "uploads")
+	defer publisher.Close()
+
+	event, err := NewUploadCompleted("/file-upload", "partner-a", fileupload.UploadResponse{Filename: "report.csv"})
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(ctx, event))
+
+	messages := srv.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, ContentType, messages[0].Attributes["content-type"])
+
+	var got Event
+	require.NoError(t, json.Unmarshal(messages[0].Data, &got))
+	require.Equal(t, event.ID, got.ID)
+}
+
+func TestNATSPublisherStartsWhileNATSIsDown(t *testing.T) {
+	publisher, err := NewNATSPublisher("nats://127.0.0.1:1", "uploads")
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	event, err := NewUploadCompleted("/file-upload", "partner-a", fileupload.UploadResponse{Filename: "report.csv"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(t, publisher.Publish(ctx, event), "an unacknowledged event must stay in the outbox")
+}
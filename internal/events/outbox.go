@@ -0,0 +1,164 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/spool"
+)
+
+const (
+	defaultRetryBase = time.Second
+	defaultRetryMax  = time.Minute
+)
+
+// OutboxConfig tunes the relay. Zero values use the defaults.
+type OutboxConfig struct {
+	Dir string
+	// RetryBase is the wait after a failed publish, doubling while the
+	// broker stays down up to RetryMax.
+	RetryBase time.Duration
+	RetryMax  time.Duration
+}
+
+// Outbox stores events in a spool directory and relays them to a publisher
+// in order, so events raised while the broker is down are sent once it is
+// back, including across restarts. Delivery is at least once.
+type Outbox struct {
+	cfg       OutboxConfig
+	publisher Publisher
+	logger    *slog.Logger
+	dir       *spool.Dir
+
+	// mu guards pending, the events on disk oldest first. The disk is only
+	// read at startup.
+	mu      sync.Mutex
+	pending []Event
+	wake    chan struct{}
+}
+
+var _ Publisher = (*Outbox)(nil)
+
+func NewOutbox(cfg OutboxConfig, publisher Publisher, logger *slog.Logger) (*Outbox, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("event outbox dir is required")
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = defaultRetryBase
+	}
+	if cfg.RetryMax <= 0 {
+		cfg.RetryMax = defaultRetryMax
+	}
+	dir, err := spool.Open(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening event outbox: %w", err)
+	}
+	pending, err := spool.Load[Event](dir)
+	if err != nil {
+		return nil, fmt.Errorf("loading event outbox: %w", err)
+	}
+	return &Outbox{
+		cfg:       cfg,
+		publisher: publisher,
+		logger:    logger,
+		dir:       dir,
+		pending:   pending,
+		wake:      make(chan struct{}, 1),
+	}, nil
+}
+
+// Publish stores event for Run to send. It returns once the event is on
+// disk, not when the broker has it.
+func (o *Outbox) Publish(ctx context.Context, event Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.dir.Put(event.ID, event); err != nil {
+		return fmt.Errorf("storing event: %w", err)
+	}
+	o.pending = append(o.pending, event)
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run relays stored events until ctx is done. After a failure it backs off
+// and retries the same event, so events reach the broker in order.
+func (o *Outbox) Run(ctx context.Context) {
+	failures := 0
+	for {
+		var retry <-chan time.Time
+		if err := o.relay(ctx); err != nil {
+			failures++
+			wait := spool.Backoff(failures, o.cfg.RetryBase, o.cfg.RetryMax)
+			o.logger.WarnContext(ctx, "publishing events failed; retrying",
+				"failures", failures,
+				"retry_in", wait,
+				"error", err,
+			)
+			retry = time.After(wait)
+		} else {
+			failures = 0
+		}
+
+		// New events don't cut a backoff short while the broker is down.
+		wake := o.wake
+		if failures > 0 {
+			wake = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-retry:
+		case <-wake:
+		}
+	}
+}
+
+// Close closes the publisher. Events still in the outbox are sent after the
+// next start.
+func (o *Outbox) Close() error {
+	return o.publisher.Close()
+}
+
+// Pending returns the number of events not yet published.
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// relay publishes stored events oldest first, stopping at the first failure.
+func (o *Outbox) relay(ctx context.Context) error {
+	for ctx.Err() == nil {
+		o.mu.Lock()
+		if len(o.pending) == 0 {
+			o.mu.Unlock()
+			return nil
+		}
+		event := o.pending[0]
+		o.mu.Unlock()
+
+		if err := o.publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+
+		o.mu.Lock()
+		o.pending = o.pending[1:]
+		err := o.dir.Remove(event.ID)
+		o.mu.Unlock()
+		if err != nil {
+			return fmt.Errorf("removing published event: %w", err)
+		}
+		o.logger.DebugContext(ctx, "event published", "id", event.ID, "type", event.Type, "subject", event.Subject)
+	}
+	return nil
+}
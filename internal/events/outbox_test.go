@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+type fakePublisher struct {
+	mu        sync.Mutex
+	down      bool
+	published []Event
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func newTestOutbox(t *testing.T, publisher Publisher) *Outbox {
+	t.Helper()
+	outbox, err := NewOutbox(OutboxConfig{Dir: t.TempDir()}, publisher, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return outbox
+}
+
+func TestOutboxKeepsEventsWhileBrokerIsDown(t *testing.T) {
+	ctx := context.Background()
+	publisher := &fakePublisher{down: true}
+	outbox := newTestOutbox(t, publisher)
+
+	var ids []string
+	for _, name := range []string{"a.csv", "b.csv", "c.csv"} {
+		event, err := NewUploadCompleted("/file-upload", "partner-a", fileupload.UploadResponse{Filename: name})
+		require.NoError(t, err)
+		require.NoError(t, outbox.Publish(ctx, event))
+		ids = append(ids, event.ID)
+	}
+
+	require.Error(t, outbox.relay(ctx))
+	require.Equal(t, 3, outbox.Pending())
+
+	publisher.down = false
+	require.NoError(t, outbox.relay(ctx))
+	require.Zero(t, outbox.Pending())
+
+	require.Len(t, publisher.published, 3)
+	for i, event := range publisher.published {
+		require.Equal(t, ids[i], event.ID)
+	}
+}
+
+func TestOutboxSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	first, err := NewOutbox(OutboxConfig{Dir: dir}, &fakePublisher{down: true}, logger)
+	require.NoError(t, err)
+	event, err := NewUploadCompleted("/file-upload", "partner-a", fileupload.UploadResponse{Filename: "a.csv"})
+	require.NoError(t, err)
+	require.NoError(t, first.Publish(ctx, event))
+
+	publisher := &fakePublisher{}
+	second, err := NewOutbox(OutboxConfig{Dir: dir}, publisher, logger)
+	require.NoError(t, err)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go second.Run(runCtx)
+
+	require.Eventually(t, func() bool {
+		publisher.mu.Lock()
+		defer publisher.mu.Unlock()
+		return len(publisher.published) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, event.ID, publisher.published[0].ID)
+	require.Eventually(t, func() bool { return second.Pending() == 0 }, time.Second, 10*time.Millisecond)
+}
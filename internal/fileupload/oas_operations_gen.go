@@ -6,5 +6,13 @@ package fileupload
 type OperationName = string
 
 const (
-	UploadFileOperation OperationName = "UploadFile"
+	DeleteFileOperation             OperationName = "DeleteFile"
+	DownloadFileOperation           OperationName = "DownloadFile"
+	GetLoadStatusOperation          OperationName = "GetLoadStatus"
+	GetLogLevelOperation            OperationName = "GetLogLevel"
+	ListFilesOperation              OperationName = "ListFiles"
+	ListWebhookDeadLettersOperation OperationName = "ListWebhookDeadLetters"
+	SetLogLevelOperation            OperationName = "SetLogLevel"
+	UploadFileOperation             OperationName = "UploadFile"
+	UploadCompletedOperation        OperationName = "UploadCompleted"
 )
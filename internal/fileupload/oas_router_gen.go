@@ -40,6 +40,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.notFound(w, r)
 		return
 	}
+	args := [1]string{}
 
 	// Static code generated router with unwrapped path search.
 	switch {
@@ -48,24 +49,191 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 		switch elem[0] {
-		case '/': // Prefix: "/upload"
+		case '/': // Prefix: "/"
 			origElem := elem
-			if l := len("/upload"); len(elem) >= l && elem[0:l] == "/upload" {
+			if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
 				elem = elem[l:]
 			} else {
 				break
 			}
 
 			if len(elem) == 0 {
-				// Leaf node.
-				switch r.Method {
-				case "POST":
-					s.handleUploadFileRequest([0]string{}, elemIsEscaped, w, r)
-				default:
-					s.notAllowed(w, r, "POST")
+				break
+			}
+			switch elem[0] {
+			case 'a': // Prefix: "admin/"
+				origElem := elem
+				if l := len("admin/"); len(elem) >= l && elem[0:l] == "admin/" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					break
+				}
+				switch elem[0] {
+				case 'l': // Prefix: "log-level"
+					origElem := elem
+					if l := len("log-level"); len(elem) >= l && elem[0:l] == "log-level" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch r.Method {
+						case "GET":
+							s.handleGetLogLevelRequest([0]string{}, elemIsEscaped, w, r)
+						case "PUT":
+							s.handleSetLogLevelRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET,PUT")
+						}
+
+						return
+					}
+
+					elem = origElem
+				case 'w': // Prefix: "webhooks/dead-letters"
+					origElem := elem
+					if l := len("webhooks/dead-letters"); len(elem) >= l && elem[0:l] == "webhooks/dead-letters" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch r.Method {
+						case "GET":
+							s.handleListWebhookDeadLettersRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET")
+						}
+
+						return
+					}
+
+					elem = origElem
+				}
+
+				elem = origElem
+			case 'f': // Prefix: "file"
+				origElem := elem
+				if l := len("file"); len(elem) >= l && elem[0:l] == "file" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					switch r.Method {
+					case "DELETE":
+						s.handleDeleteFileRequest([0]string{}, elemIsEscaped, w, r)
+					case "GET":
+						s.handleDownloadFileRequest([0]string{}, elemIsEscaped, w, r)
+					default:
+						s.notAllowed(w, r, "DELETE,GET")
+					}
+
+					return
+				}
+				switch elem[0] {
+				case 's': // Prefix: "s"
+					origElem := elem
+					if l := len("s"); len(elem) >= l && elem[0:l] == "s" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch r.Method {
+						case "GET":
+							s.handleListFilesRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET")
+						}
+
+						return
+					}
+
+					elem = origElem
+				}
+
+				elem = origElem
+			case 'u': // Prefix: "upload"
+				origElem := elem
+				if l := len("upload"); len(elem) >= l && elem[0:l] == "upload" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					switch r.Method {
+					case "POST":
+						s.handleUploadFileRequest([0]string{}, elemIsEscaped, w, r)
+					default:
+						s.notAllowed(w, r, "POST")
+					}
+
+					return
 				}
+				switch elem[0] {
+				case 's': // Prefix: "s/"
+					origElem := elem
+					if l := len("s/"); len(elem) >= l && elem[0:l] == "s/" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					// Param: "id"
+					// Match until "/"
+					idx := strings.IndexByte(elem, '/')
+					if idx < 0 {
+						idx = len(elem)
+					}
+					args[0] = elem[:idx]
+					elem = elem[idx:]
+
+					if len(elem) == 0 {
+						break
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/load-status"
+						origElem := elem
+						if l := len("/load-status"); len(elem) >= l && elem[0:l] == "/load-status" {
+							elem = elem[l:]
+						} else {
+							break
+						}
 
-				return
+						if len(elem) == 0 {
+							// Leaf node.
+							switch r.Method {
+							case "GET":
+								s.handleGetLoadStatusRequest([1]string{
+									args[0],
+								}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "GET")
+							}
+
+							return
+						}
+
+						elem = origElem
+					}
+
+					elem = origElem
+				}
+
+				elem = origElem
 			}
 
 			elem = origElem
@@ -81,7 +249,7 @@ type Route struct {
 	operationID string
 	pathPattern string
 	count       int
-	args        [0]string
+	args        [1]string
 }
 
 // Name returns ogen operation name.
@@ -149,28 +317,225 @@ func (s *Server) FindPath(method string, u *url.URL) (r Route, _ bool) {
 			break
 		}
 		switch elem[0] {
-		case '/': // Prefix: "/upload"
+		case '/': // Prefix: "/"
 			origElem := elem
-			if l := len("/upload"); len(elem) >= l && elem[0:l] == "/upload" {
+			if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
 				elem = elem[l:]
 			} else {
 				break
 			}
 
 			if len(elem) == 0 {
-				// Leaf node.
-				switch method {
-				case "POST":
-					r.name = UploadFileOperation
-					r.summary = "Upload a spreadsheet file to Google Cloud Storage"
-					r.operationID = "uploadFile"
-					r.pathPattern = "/upload"
-					r.args = args
-					r.count = 0
-					return r, true
-				default:
-					return
+				break
+			}
+			switch elem[0] {
+			case 'a': // Prefix: "admin/"
+				origElem := elem
+				if l := len("admin/"); len(elem) >= l && elem[0:l] == "admin/" {
+					elem = elem[l:]
+				} else {
+					break
 				}
+
+				if len(elem) == 0 {
+					break
+				}
+				switch elem[0] {
+				case 'l': // Prefix: "log-level"
+					origElem := elem
+					if l := len("log-level"); len(elem) >= l && elem[0:l] == "log-level" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch method {
+						case "GET":
+							r.name = GetLogLevelOperation
+							r.summary = "Get the log level"
+							r.operationID = "getLogLevel"
+							r.pathPattern = "/admin/log-level"
+							r.args = args
+							r.count = 0
+							return r, true
+						case "PUT":
+							r.name = SetLogLevelOperation
+							r.summary = "Change the log level"
+							r.operationID = "setLogLevel"
+							r.pathPattern = "/admin/log-level"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+
+					elem = origElem
+				case 'w': // Prefix: "webhooks/dead-letters"
+					origElem := elem
+					if l := len("webhooks/dead-letters"); len(elem) >= l && elem[0:l] == "webhooks/dead-letters" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch method {
+						case "GET":
+							r.name = ListWebhookDeadLettersOperation
+							r.summary = "List undeliverable webhooks"
+							r.operationID = "listWebhookDeadLetters"
+							r.pathPattern = "/admin/webhooks/dead-letters"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+
+					elem = origElem
+				}
+
+				elem = origElem
+			case 'f': // Prefix: "file"
+				origElem := elem
+				if l := len("file"); len(elem) >= l && elem[0:l] == "file" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					switch method {
+					case "DELETE":
+						r.name = DeleteFileOperation
+						r.summary = "Delete a stored file"
+						r.operationID = "deleteFile"
+						r.pathPattern = "/file"
+						r.args = args
+						r.count = 0
+						return r, true
+					case "GET":
+						r.name = DownloadFileOperation
+						r.summary = "Download a stored file"
+						r.operationID = "downloadFile"
+						r.pathPattern = "/file"
+						r.args = args
+						r.count = 0
+						return r, true
+					default:
+						return
+					}
+				}
+				switch elem[0] {
+				case 's': // Prefix: "s"
+					origElem := elem
+					if l := len("s"); len(elem) >= l && elem[0:l] == "s" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch method {
+						case "GET":
+							r.name = ListFilesOperation
+							r.summary = "List stored files"
+							r.operationID = "listFiles"
+							r.pathPattern = "/files"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+
+					elem = origElem
+				}
+
+				elem = origElem
+			case 'u': // Prefix: "upload"
+				origElem := elem
+				if l := len("upload"); len(elem) >= l && elem[0:l] == "upload" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					switch method {
+					case "POST":
+						r.name = UploadFileOperation
+						r.summary = "Upload a spreadsheet file to Google Cloud Storage"
+						r.operationID = "uploadFile"
+						r.pathPattern = "/upload"
+						r.args = args
+						r.count = 0
+						return r, true
+					default:
+						return
+					}
+				}
+				switch elem[0] {
+				case 's': // Prefix: "s/"
+					origElem := elem
+					if l := len("s/"); len(elem) >= l && elem[0:l] == "s/" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					// Param: "id"
+					// Match until "/"
+					idx := strings.IndexByte(elem, '/')
+					if idx < 0 {
+						idx = len(elem)
+					}
+					args[0] = elem[:idx]
+					elem = elem[idx:]
+
+					if len(elem) == 0 {
+						break
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/load-status"
+						origElem := elem
+						if l := len("/load-status"); len(elem) >= l && elem[0:l] == "/load-status" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch method {
+							case "GET":
+								r.name = GetLoadStatusOperation
+								r.summary = "Get the status of a BigQuery load"
+								r.operationID = "getLoadStatus"
+								r.pathPattern = "/uploads/{id}/load-status"
+								r.args = args
+								r.count = 1
+								return r, true
+							default:
+								return
+							}
+						}
+
+						elem = origElem
+					}
+
+					elem = origElem
+				}
+
+				elem = origElem
 			}
 
 			elem = origElem
@@ -178,3 +543,40 @@ func (s *Server) FindPath(method string, u *url.URL) (r Route, _ bool) {
 	}
 	return r, false
 }
+
+// Handle handles webhook request.
+//
+// Returns true if there is a webhook handler for given name and requested method.
+func (s *WebhookServer) Handle(webhookName string, w http.ResponseWriter, r *http.Request) bool {
+	switch webhookName {
+	case "uploadCompleted":
+		switch r.Method {
+		case "POST":
+			s.handleUploadCompletedRequest([0]string{}, false, w, r)
+		default:
+			return false
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// Handler returns http.Handler for webhook.
+//
+// Returns NotFound handler if spec doesn't contain webhook with given name.
+//
+// Returned handler calls MethodNotAllowed handler if webhook doesn't define requested method.
+func (s *WebhookServer) Handler(webhookName string) http.Handler {
+	switch webhookName {
+	case "uploadCompleted":
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// We know that webhook exists, so false means wrong method.
+			if !s.Handle(webhookName, w, r) {
+				s.notAllowed(w, r, "POST")
+			}
+		})
+	default:
+		return http.HandlerFunc(s.notFound)
+	}
+}
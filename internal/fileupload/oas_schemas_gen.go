@@ -4,8 +4,11 @@ package fileupload
 
 import (
 	"fmt"
+	"io"
 	"time"
 
+	"github.com/go-faster/errors"
+
 	ht "github.com/ogen-go/ogen/http"
 )
 
@@ -13,6 +16,20 @@ func (s *ErrorStatusCodeWithHeaders) Error() string {
 	return fmt.Sprintf("code %d: %+v", s.StatusCode, s.Response)
 }
 
+type ApiKeyAuth struct {
+	APIKey string
+}
+
+// GetAPIKey returns the value of APIKey.
+func (s *ApiKeyAuth) GetAPIKey() string {
+	return s.APIKey
+}
+
+// SetAPIKey sets the value of APIKey.
+func (s *ApiKeyAuth) SetAPIKey(val string) {
+	s.APIKey = val
+}
+
 type BasicAuth struct {
 	Username string
 	Password string
@@ -38,6 +55,63 @@ func (s *BasicAuth) SetPassword(val string) {
 	s.Password = val
 }
 
+type BearerAuth struct {
+	Token string
+}
+
+// GetToken returns the value of Token.
+func (s *BearerAuth) GetToken() string {
+	return s.Token
+}
+
+// SetToken sets the value of Token.
+func (s *BearerAuth) SetToken(val string) {
+	s.Token = val
+}
+
+// DeleteFileNoContent is response for DeleteFile operation.
+type DeleteFileNoContent struct{}
+
+type DownloadFileOK struct {
+	Data io.Reader
+}
+
+// Read reads data from the Data reader.
+//
+// Kept to satisfy the io.Reader interface.
+func (s DownloadFileOK) Read(p []byte) (n int, err error) {
+	if s.Data == nil {
+		return 0, io.EOF
+	}
+	return s.Data.Read(p)
+}
+
+// DownloadFileOKHeaders wraps DownloadFileOK with response headers.
+type DownloadFileOKHeaders struct {
+	ContentDisposition OptString
+	Response           DownloadFileOK
+}
+
+// GetContentDisposition returns the value of ContentDisposition.
+func (s *DownloadFileOKHeaders) GetContentDisposition() OptString {
+	return s.ContentDisposition
+}
+
+// GetResponse returns the value of Response.
+func (s *DownloadFileOKHeaders) GetResponse() DownloadFileOK {
+	return s.Response
+}
+
+// SetContentDisposition sets the value of ContentDisposition.
+func (s *DownloadFileOKHeaders) SetContentDisposition(val OptString) {
+	s.ContentDisposition = val
+}
+
+// SetResponse sets the value of Response.
+func (s *DownloadFileOKHeaders) SetResponse(val DownloadFileOK) {
+	s.Response = val
+}
+
 // Ref: #/components/schemas/Error
 type Error struct {
 	// HTTP status code.
@@ -78,10 +152,39 @@ func (s *Error) SetDetails(val []string) {
 	s.Details = val
 }
 
+// ErrorHeaders wraps Error with response headers.
+type ErrorHeaders struct {
+	RetryAfter OptInt
+	Response   Error
+}
+
+// GetRetryAfter returns the value of RetryAfter.
+func (s *ErrorHeaders) GetRetryAfter() OptInt {
+	return s.RetryAfter
+}
+
+// GetResponse returns the value of Response.
+func (s *ErrorHeaders) GetResponse() Error {
+	return s.Response
+}
+
+// SetRetryAfter sets the value of RetryAfter.
+func (s *ErrorHeaders) SetRetryAfter(val OptInt) {
+	s.RetryAfter = val
+}
+
+// SetResponse sets the value of Response.
+func (s *ErrorHeaders) SetResponse(val Error) {
+	s.Response = val
+}
+
+func (*ErrorHeaders) uploadFileRes() {}
+
 // ErrorStatusCodeWithHeaders wraps Error with status code and response headers.
 type ErrorStatusCodeWithHeaders struct {
 	StatusCode               int
 	AccessControlAllowOrigin OptString
+	RetryAfter               OptInt
 	Response                 Error
 }
 
@@ -95,6 +198,11 @@ func (s *ErrorStatusCodeWithHeaders) GetAccessControlAllowOrigin() OptString {
 	return s.AccessControlAllowOrigin
 }
 
+// GetRetryAfter returns the value of RetryAfter.
+func (s *ErrorStatusCodeWithHeaders) GetRetryAfter() OptInt {
+	return s.RetryAfter
+}
+
 // GetResponse returns the value of Response.
 func (s *ErrorStatusCodeWithHeaders) GetResponse() Error {
 	return s.Response
@@ -110,11 +218,422 @@ func (s *ErrorStatusCodeWithHeaders) SetAccessControlAllowOrigin(val OptString)
 	s.AccessControlAllowOrigin = val
 }
 
+// SetRetryAfter sets the value of RetryAfter.
+func (s *ErrorStatusCodeWithHeaders) SetRetryAfter(val OptInt) {
+	s.RetryAfter = val
+}
+
 // SetResponse sets the value of Response.
 func (s *ErrorStatusCodeWithHeaders) SetResponse(val Error) {
 	s.Response = val
 }
 
+// Ref: #/components/schemas/FileInfo
+type FileInfo struct {
+	// Full object name.
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	ContentType OptString `json:"contentType"`
+	Updated     time.Time `json:"updated"`
+}
+
+// GetName returns the value of Name.
+func (s *FileInfo) GetName() string {
+	return s.Name
+}
+
+// GetSize returns the value of Size.
+func (s *FileInfo) GetSize() int64 {
+	return s.Size
+}
+
+// GetContentType returns the value of ContentType.
+func (s *FileInfo) GetContentType() OptString {
+	return s.ContentType
+}
+
+// GetUpdated returns the value of Updated.
+func (s *FileInfo) GetUpdated() time.Time {
+	return s.Updated
+}
+
+// SetName sets the value of Name.
+func (s *FileInfo) SetName(val string) {
+	s.Name = val
+}
+
+// SetSize sets the value of Size.
+func (s *FileInfo) SetSize(val int64) {
+	s.Size = val
+}
+
+// SetContentType sets the value of ContentType.
+func (s *FileInfo) SetContentType(val OptString) {
+	s.ContentType = val
+}
+
+// SetUpdated sets the value of Updated.
+func (s *FileInfo) SetUpdated(val time.Time) {
+	s.Updated = val
+}
+
+// Ref: #/components/schemas/FileList
+type FileList struct {
+	Files []FileInfo `json:"files"`
+	// Pass as `pageToken` to fetch the next page; absent on the last page.
+	NextPageToken OptString `json:"nextPageToken"`
+}
+
+// GetFiles returns the value of Files.
+func (s *FileList) GetFiles() []FileInfo {
+	return s.Files
+}
+
+// GetNextPageToken returns the value of NextPageToken.
+func (s *FileList) GetNextPageToken() OptString {
+	return s.NextPageToken
+}
+
+// SetFiles sets the value of Files.
+func (s *FileList) SetFiles(val []FileInfo) {
+	s.Files = val
+}
+
+// SetNextPageToken sets the value of NextPageToken.
+func (s *FileList) SetNextPageToken(val OptString) {
+	s.NextPageToken = val
+}
+
+// Ref: #/components/schemas/LoadStatus
+type LoadStatus struct {
+	JobId string          `json:"jobId"`
+	State LoadStatusState `json:"state"`
+	// Uploaded object the job loads.
+	Object string `json:"object"`
+	// Destination table as `project.dataset.table`.
+	Table string `json:"table"`
+	// Rows loaded, once the job is done.
+	OutputRows OptInt64 `json:"outputRows"`
+	// Errors of a failed job; a job that is DONE without errors succeeded.
+	Errors []string `json:"errors"`
+}
+
+// GetJobId returns the value of JobId.
+func (s *LoadStatus) GetJobId() string {
+	return s.JobId
+}
+
+// GetState returns the value of State.
+func (s *LoadStatus) GetState() LoadStatusState {
+	return s.State
+}
+
+// GetObject returns the value of Object.
+func (s *LoadStatus) GetObject() string {
+	return s.Object
+}
+
+// GetTable returns the value of Table.
+func (s *LoadStatus) GetTable() string {
+	return s.Table
+}
+
+// GetOutputRows returns the value of OutputRows.
+func (s *LoadStatus) GetOutputRows() OptInt64 {
+	return s.OutputRows
+}
+
+// GetErrors returns the value of Errors.
+func (s *LoadStatus) GetErrors() []string {
+	return s.Errors
+}
+
+// SetJobId sets the value of JobId.
+func (s *LoadStatus) SetJobId(val string) {
+	s.JobId = val
+}
+
+// SetState sets the value of State.
+func (s *LoadStatus) SetState(val LoadStatusState) {
+	s.State = val
+}
+
+// SetObject sets the value of Object.
+func (s *LoadStatus) SetObject(val string) {
+	s.Object = val
+}
+
+// SetTable sets the value of Table.
+func (s *LoadStatus) SetTable(val string) {
+	s.Table = val
+}
+
+// SetOutputRows sets the value of OutputRows.
+func (s *LoadStatus) SetOutputRows(val OptInt64) {
+	s.OutputRows = val
+}
+
+// SetErrors sets the value of Errors.
+func (s *LoadStatus) SetErrors(val []string) {
+	s.Errors = val
+}
+
+type LoadStatusState string
+
+const (
+	LoadStatusStatePENDING LoadStatusState = "PENDING"
+	LoadStatusStateRUNNING LoadStatusState = "RUNNING"
+	LoadStatusStateDONE    LoadStatusState = "DONE"
+)
+
+// AllValues returns all LoadStatusState values.
+func (LoadStatusState) AllValues() []LoadStatusState {
+	return []LoadStatusState{
+		LoadStatusStatePENDING,
+		LoadStatusStateRUNNING,
+		LoadStatusStateDONE,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s LoadStatusState) MarshalText() ([]byte, error) {
+	switch s {
+	case LoadStatusStatePENDING:
+		return []byte(s), nil
+	case LoadStatusStateRUNNING:
+		return []byte(s), nil
+	case LoadStatusStateDONE:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *LoadStatusState) UnmarshalText(data []byte) error {
+	switch LoadStatusState(data) {
+	case LoadStatusStatePENDING:
+		*s = LoadStatusStatePENDING
+		return nil
+	case LoadStatusStateRUNNING:
+		*s = LoadStatusStateRUNNING
+		return nil
+	case LoadStatusStateDONE:
+		*s = LoadStatusStateDONE
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Ref: #/components/schemas/LogLevel
+type LogLevel struct {
+	Level LogLevelLevel `json:"level"`
+}
+
+// GetLevel returns the value of Level.
+func (s *LogLevel) GetLevel() LogLevelLevel {
+	return s.Level
+}
+
+// SetLevel sets the value of Level.
+func (s *LogLevel) SetLevel(val LogLevelLevel) {
+	s.Level = val
+}
+
+type LogLevelLevel string
+
+const (
+	LogLevelLevelDEBUG LogLevelLevel = "DEBUG"
+	LogLevelLevelINFO  LogLevelLevel = "INFO"
+	LogLevelLevelWARN  LogLevelLevel = "WARN"
+	LogLevelLevelERROR LogLevelLevel = "ERROR"
+)
+
+// AllValues returns all LogLevelLevel values.
+func (LogLevelLevel) AllValues() []LogLevelLevel {
+	return []LogLevelLevel{
+		LogLevelLevelDEBUG,
+		LogLevelLevelINFO,
+		LogLevelLevelWARN,
+		LogLevelLevelERROR,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s LogLevelLevel) MarshalText() ([]byte, error) {
+	switch s {
+	case LogLevelLevelDEBUG:
+		return []byte(s), nil
+	case LogLevelLevelINFO:
+		return []byte(s), nil
+	case LogLevelLevelWARN:
+		return []byte(s), nil
+	case LogLevelLevelERROR:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *LogLevelLevel) UnmarshalText(data []byte) error {
+	switch LogLevelLevel(data) {
+	case LogLevelLevelDEBUG:
+		*s = LogLevelLevelDEBUG
+		return nil
+	case LogLevelLevelINFO:
+		*s = LogLevelLevelINFO
+		return nil
+	case LogLevelLevelWARN:
+		*s = LogLevelLevelWARN
+		return nil
+	case LogLevelLevelERROR:
+		*s = LogLevelLevelERROR
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// NewOptDateTime returns new OptDateTime with value set to v.
+func NewOptDateTime(v time.Time) OptDateTime {
+	return OptDateTime{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptDateTime is optional time.Time.
+type OptDateTime struct {
+	Value time.Time
+	Set   bool
+}
+
+// IsSet returns true if OptDateTime was set.
+func (o OptDateTime) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptDateTime) Reset() {
+	var v time.Time
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptDateTime) SetTo(v time.Time) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptDateTime) Get() (v time.Time, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptDateTime) Or(d time.Time) time.Time {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInt returns new OptInt with value set to v.
+func NewOptInt(v int) OptInt {
+	return OptInt{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInt is optional int.
+type OptInt struct {
+	Value int
+	Set   bool
+}
+
+// IsSet returns true if OptInt was set.
+func (o OptInt) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInt) Reset() {
+	var v int
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInt) SetTo(v int) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInt) Get() (v int, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInt) Or(d int) int {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInt64 returns new OptInt64 with value set to v.
+func NewOptInt64(v int64) OptInt64 {
+	return OptInt64{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInt64 is optional int64.
+type OptInt64 struct {
+	Value int64
+	Set   bool
+}
+
+// IsSet returns true if OptInt64 was set.
+func (o OptInt64) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInt64) Reset() {
+	var v int64
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInt64) SetTo(v int64) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInt64) Get() (v int64, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInt64) Or(d int64) int64 {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
 // NewOptString returns new OptString with value set to v.
 func NewOptString(v string) OptString {
 	return OptString{
@@ -161,10 +680,285 @@ func (o OptString) Or(d string) string {
 	return d
 }
 
+// Ref: #/components/schemas/SensitiveColumn
+type SensitiveColumn struct {
+	// Zero-based column index.
+	Index int `json:"index"`
+	// Column header.
+	Name string `json:"name"`
+	// Kinds of sensitive data detected in the column.
+	Kinds []SensitiveColumnKindsItem `json:"kinds"`
+	// Number of affected cells.
+	Cells int `json:"cells"`
+	// Action applied to the affected cells.
+	Action SensitiveColumnAction `json:"action"`
+}
+
+// GetIndex returns the value of Index.
+func (s *SensitiveColumn) GetIndex() int {
+	return s.Index
+}
+
+// GetName returns the value of Name.
+func (s *SensitiveColumn) GetName() string {
+	return s.Name
+}
+
+// GetKinds returns the value of Kinds.
+func (s *SensitiveColumn) GetKinds() []SensitiveColumnKindsItem {
+	return s.Kinds
+}
+
+// GetCells returns the value of Cells.
+func (s *SensitiveColumn) GetCells() int {
+	return s.Cells
+}
+
+// GetAction returns the value of Action.
+func (s *SensitiveColumn) GetAction() SensitiveColumnAction {
+	return s.Action
+}
+
+// SetIndex sets the value of Index.
+func (s *SensitiveColumn) SetIndex(val int) {
+	s.Index = val
+}
+
+// SetName sets the value of Name.
+func (s *SensitiveColumn) SetName(val string) {
+	s.Name = val
+}
+
+// SetKinds sets the value of Kinds.
+func (s *SensitiveColumn) SetKinds(val []SensitiveColumnKindsItem) {
+	s.Kinds = val
+}
+
+// SetCells sets the value of Cells.
+func (s *SensitiveColumn) SetCells(val int) {
+	s.Cells = val
+}
+
+// SetAction sets the value of Action.
+func (s *SensitiveColumn) SetAction(val SensitiveColumnAction) {
+	s.Action = val
+}
+
+// Action applied to the affected cells.
+type SensitiveColumnAction string
+
+const (
+	SensitiveColumnActionReject   SensitiveColumnAction = "reject"
+	SensitiveColumnActionMask     SensitiveColumnAction = "mask"
+	SensitiveColumnActionTokenise SensitiveColumnAction = "tokenise"
+)
+
+// AllValues returns all SensitiveColumnAction values.
+func (SensitiveColumnAction) AllValues() []SensitiveColumnAction {
+	return []SensitiveColumnAction{
+		SensitiveColumnActionReject,
+		SensitiveColumnActionMask,
+		SensitiveColumnActionTokenise,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s SensitiveColumnAction) MarshalText() ([]byte, error) {
+	switch s {
+	case SensitiveColumnActionReject:
+		return []byte(s), nil
+	case SensitiveColumnActionMask:
+		return []byte(s), nil
+	case SensitiveColumnActionTokenise:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *SensitiveColumnAction) UnmarshalText(data []byte) error {
+	switch SensitiveColumnAction(data) {
+	case SensitiveColumnActionReject:
+		*s = SensitiveColumnActionReject
+		return nil
+	case SensitiveColumnActionMask:
+		*s = SensitiveColumnActionMask
+		return nil
+	case SensitiveColumnActionTokenise:
+		*s = SensitiveColumnActionTokenise
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+type SensitiveColumnKindsItem string
+
+const (
+	SensitiveColumnKindsItemPan   SensitiveColumnKindsItem = "pan"
+	SensitiveColumnKindsItemIban  SensitiveColumnKindsItem = "iban"
+	SensitiveColumnKindsItemEmail SensitiveColumnKindsItem = "email"
+)
+
+// AllValues returns all SensitiveColumnKindsItem values.
+func (SensitiveColumnKindsItem) AllValues() []SensitiveColumnKindsItem {
+	return []SensitiveColumnKindsItem{
+		SensitiveColumnKindsItemPan,
+		SensitiveColumnKindsItemIban,
+		SensitiveColumnKindsItemEmail,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s SensitiveColumnKindsItem) MarshalText() ([]byte, error) {
+	switch s {
+	case SensitiveColumnKindsItemPan:
+		return []byte(s), nil
+	case SensitiveColumnKindsItemIban:
+		return []byte(s), nil
+	case SensitiveColumnKindsItemEmail:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *SensitiveColumnKindsItem) UnmarshalText(data []byte) error {
+	switch SensitiveColumnKindsItem(data) {
+	case SensitiveColumnKindsItemPan:
+		*s = SensitiveColumnKindsItemPan
+		return nil
+	case SensitiveColumnKindsItemIban:
+		*s = SensitiveColumnKindsItemIban
+		return nil
+	case SensitiveColumnKindsItemEmail:
+		*s = SensitiveColumnKindsItemEmail
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// UploadCompleted2XX is 2XX pattern response for UploadCompleted operation.
+type UploadCompleted2XX struct {
+	StatusCode int
+}
+
+// GetStatusCode returns the value of StatusCode.
+func (s *UploadCompleted2XX) GetStatusCode() int {
+	return s.StatusCode
+}
+
+// SetStatusCode sets the value of StatusCode.
+func (s *UploadCompleted2XX) SetStatusCode(val int) {
+	s.StatusCode = val
+}
+
+// Ref: #/components/schemas/UploadEvent
+type UploadEvent struct {
+	// Unique event id, repeated on retries.
+	ID   string          `json:"id"`
+	Type UploadEventType `json:"type"`
+	Time time.Time       `json:"time"`
+	// Principal that uploaded the file.
+	Uploader string         `json:"uploader"`
+	Upload   UploadResponse `json:"upload"`
+}
+
+// GetID returns the value of ID.
+func (s *UploadEvent) GetID() string {
+	return s.ID
+}
+
+// GetType returns the value of Type.
+func (s *UploadEvent) GetType() UploadEventType {
+	return s.Type
+}
+
+// GetTime returns the value of Time.
+func (s *UploadEvent) GetTime() time.Time {
+	return s.Time
+}
+
+// GetUploader returns the value of Uploader.
+func (s *UploadEvent) GetUploader() string {
+	return s.Uploader
+}
+
+// GetUpload returns the value of Upload.
+func (s *UploadEvent) GetUpload() UploadResponse {
+	return s.Upload
+}
+
+// SetID sets the value of ID.
+func (s *UploadEvent) SetID(val string) {
+	s.ID = val
+}
+
+// SetType sets the value of Type.
+func (s *UploadEvent) SetType(val UploadEventType) {
+	s.Type = val
+}
+
+// SetTime sets the value of Time.
+func (s *UploadEvent) SetTime(val time.Time) {
+	s.Time = val
+}
+
+// SetUploader sets the value of Uploader.
+func (s *UploadEvent) SetUploader(val string) {
+	s.Uploader = val
+}
+
+// SetUpload sets the value of Upload.
+func (s *UploadEvent) SetUpload(val UploadResponse) {
+	s.Upload = val
+}
+
+type UploadEventType string
+
+const (
+	UploadEventTypeUploadCompleted UploadEventType = "upload.completed"
+)
+
+// AllValues returns all UploadEventType values.
+func (UploadEventType) AllValues() []UploadEventType {
+	return []UploadEventType{
+		UploadEventTypeUploadCompleted,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s UploadEventType) MarshalText() ([]byte, error) {
+	switch s {
+	case UploadEventTypeUploadCompleted:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *UploadEventType) UnmarshalText(data []byte) error {
+	switch UploadEventType(data) {
+	case UploadEventTypeUploadCompleted:
+		*s = UploadEventTypeUploadCompleted
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
 type UploadFileBadRequest Error
 
 func (*UploadFileBadRequest) uploadFileRes() {}
 
+type UploadFileForbidden Error
+
+func (*UploadFileForbidden) uploadFileRes() {}
+
 type UploadFileInternalServerError Error
 
 func (*UploadFileInternalServerError) uploadFileRes() {}
@@ -172,6 +966,8 @@ func (*UploadFileInternalServerError) uploadFileRes() {}
 type UploadFileReq struct {
 	// Spreadsheet file to upload (CSV or XLSX).
 	File ht.MultipartFile `json:"file"`
+	// Optional folder to store the file under, e.g. `partner-a/`.
+	Prefix OptString `json:"prefix"`
 }
 
 // GetFile returns the value of File.
@@ -179,11 +975,21 @@ func (s *UploadFileReq) GetFile() ht.MultipartFile {
 	return s.File
 }
 
+// GetPrefix returns the value of Prefix.
+func (s *UploadFileReq) GetPrefix() OptString {
+	return s.Prefix
+}
+
 // SetFile sets the value of File.
 func (s *UploadFileReq) SetFile(val ht.MultipartFile) {
 	s.File = val
 }
 
+// SetPrefix sets the value of Prefix.
+func (s *UploadFileReq) SetPrefix(val OptString) {
+	s.Prefix = val
+}
+
 type UploadFileUnauthorized Error
 
 func (*UploadFileUnauthorized) uploadFileRes() {}
@@ -194,12 +1000,18 @@ type UploadResponse struct {
 	Filename string `json:"filename"`
 	// Size of the uploaded file in bytes.
 	FileSize int64 `json:"fileSize"`
+	// Hex encoded SHA-256 of the file as received, before any redaction or encryption.
+	SHA256 OptString `json:"sha256"`
 	// GCS bucket where the file was stored.
 	Bucket string `json:"bucket"`
 	// GCS Path.
 	Gcspath string `json:"gcspath"`
 	// Timestamp when the file was uploaded.
 	UploadTime time.Time `json:"uploadTime"`
+	// CSV columns in which sensitive data was detected and redacted.
+	SensitiveColumns []SensitiveColumn `json:"sensitiveColumns"`
+	// BigQuery load job started for the file, if it matched a load rule.
+	LoadJobId OptString `json:"loadJobId"`
 }
 
 // GetFilename returns the value of Filename.
@@ -212,6 +1024,11 @@ func (s *UploadResponse) GetFileSize() int64 {
 	return s.FileSize
 }
 
+// GetSHA256 returns the value of SHA256.
+func (s *UploadResponse) GetSHA256() OptString {
+	return s.SHA256
+}
+
 // GetBucket returns the value of Bucket.
 func (s *UploadResponse) GetBucket() string {
 	return s.Bucket
@@ -227,6 +1044,16 @@ func (s *UploadResponse) GetUploadTime() time.Time {
 	return s.UploadTime
 }
 
+// GetSensitiveColumns returns the value of SensitiveColumns.
+func (s *UploadResponse) GetSensitiveColumns() []SensitiveColumn {
+	return s.SensitiveColumns
+}
+
+// GetLoadJobId returns the value of LoadJobId.
+func (s *UploadResponse) GetLoadJobId() OptString {
+	return s.LoadJobId
+}
+
 // SetFilename sets the value of Filename.
 func (s *UploadResponse) SetFilename(val string) {
 	s.Filename = val
@@ -237,6 +1064,11 @@ func (s *UploadResponse) SetFileSize(val int64) {
 	s.FileSize = val
 }
 
+// SetSHA256 sets the value of SHA256.
+func (s *UploadResponse) SetSHA256(val OptString) {
+	s.SHA256 = val
+}
+
 // SetBucket sets the value of Bucket.
 func (s *UploadResponse) SetBucket(val string) {
 	s.Bucket = val
@@ -252,6 +1084,16 @@ func (s *UploadResponse) SetUploadTime(val time.Time) {
 	s.UploadTime = val
 }
 
+// SetSensitiveColumns sets the value of SensitiveColumns.
+func (s *UploadResponse) SetSensitiveColumns(val []SensitiveColumn) {
+	s.SensitiveColumns = val
+}
+
+// SetLoadJobId sets the value of LoadJobId.
+func (s *UploadResponse) SetLoadJobId(val OptString) {
+	s.LoadJobId = val
+}
+
 // UploadResponseHeaders wraps UploadResponse with response headers.
 type UploadResponseHeaders struct {
 	AccessControlAllowOrigin OptString
@@ -279,3 +1121,89 @@ func (s *UploadResponseHeaders) SetResponse(val UploadResponse) {
 }
 
 func (*UploadResponseHeaders) uploadFileRes() {}
+
+// Ref: #/components/schemas/WebhookDelivery
+type WebhookDelivery struct {
+	ID string `json:"id"`
+	// Name of the subscription.
+	Subscription string      `json:"subscription"`
+	Event        UploadEvent `json:"event"`
+	Attempts     int         `json:"attempts"`
+	LastAttempt  OptDateTime `json:"lastAttempt"`
+	LastError    OptString   `json:"lastError"`
+}
+
+// GetID returns the value of ID.
+func (s *WebhookDelivery) GetID() string {
+	return s.ID
+}
+
+// GetSubscription returns the value of Subscription.
+func (s *WebhookDelivery) GetSubscription() string {
+	return s.Subscription
+}
+
+// GetEvent returns the value of Event.
+func (s *WebhookDelivery) GetEvent() UploadEvent {
+	return s.Event
+}
+
+// GetAttempts returns the value of Attempts.
+func (s *WebhookDelivery) GetAttempts() int {
+	return s.Attempts
+}
+
+// GetLastAttempt returns the value of LastAttempt.
+func (s *WebhookDelivery) GetLastAttempt() OptDateTime {
+	return s.LastAttempt
+}
+
+// GetLastError returns the value of LastError.
+func (s *WebhookDelivery) GetLastError() OptString {
+	return s.LastError
+}
+
+// SetID sets the value of ID.
+func (s *WebhookDelivery) SetID(val string) {
+	s.ID = val
+}
+
+// SetSubscription sets the value of Subscription.
+func (s *WebhookDelivery) SetSubscription(val string) {
+	s.Subscription = val
+}
+
+// SetEvent sets the value of Event.
+func (s *WebhookDelivery) SetEvent(val UploadEvent) {
+	s.Event = val
+}
+
+// SetAttempts sets the value of Attempts.
+func (s *WebhookDelivery) SetAttempts(val int) {
+	s.Attempts = val
+}
+
+// SetLastAttempt sets the value of LastAttempt.
+func (s *WebhookDelivery) SetLastAttempt(val OptDateTime) {
+	s.LastAttempt = val
+}
+
+// SetLastError sets the value of LastError.
+func (s *WebhookDelivery) SetLastError(val OptString) {
+	s.LastError = val
+}
+
+// Ref: #/components/schemas/WebhookDeliveryList
+type WebhookDeliveryList struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// GetDeliveries returns the value of Deliveries.
+func (s *WebhookDeliveryList) GetDeliveries() []WebhookDelivery {
+	return s.Deliveries
+}
+
+// SetDeliveries sets the value of Deliveries.
+func (s *WebhookDeliveryList) SetDeliveries(val []WebhookDelivery) {
+	s.Deliveries = val
+}
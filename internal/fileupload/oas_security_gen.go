@@ -14,10 +14,20 @@ import (
 
 // SecurityHandler is handler for security parameters.
 type SecurityHandler interface {
+	// HandleApiKeyAuth handles apiKeyAuth security.
+	// API key issued to a machine client, in the form `fuk_<prefix>_<secret>`.
+	HandleApiKeyAuth(ctx context.Context, operationName OperationName, t ApiKeyAuth) (context.Context, error)
 	// HandleBasicAuth handles basicAuth security.
 	// Requires valid username/password combination.
 	// Credentials must be base64 encoded in the Authorization header.
 	HandleBasicAuth(ctx context.Context, operationName OperationName, t BasicAuth) (context.Context, error)
+	// HandleBearerAuth handles bearerAuth security.
+	// OIDC ID token (e.g. Google or Keycloak) verified against the issuer's JWKS.
+	HandleBearerAuth(ctx context.Context, operationName OperationName, t BearerAuth) (context.Context, error)
+	// HandleMutualTLS handles mutualTLS security.
+	// Client certificate verified during the TLS handshake. Only used when no
+	// other credentials are sent.
+	HandleMutualTLS(ctx context.Context, operationName OperationName, req *http.Request) (context.Context, error)
 }
 
 func findAuthorization(h http.Header, prefix string) (string, bool) {
@@ -35,6 +45,22 @@ func findAuthorization(h http.Header, prefix string) (string, bool) {
 	return "", false
 }
 
+func (s *Server) securityApiKeyAuth(ctx context.Context, operationName OperationName, req *http.Request) (context.Context, bool, error) {
+	var t ApiKeyAuth
+	const parameterName = "X-API-Key"
+	value := req.Header.Get(parameterName)
+	if value == "" {
+		return ctx, false, nil
+	}
+	t.APIKey = value
+	rctx, err := s.sec.HandleApiKeyAuth(ctx, operationName, t)
+	if errors.Is(err, ogenerrors.ErrSkipServerSecurity) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return rctx, true, err
+}
 func (s *Server) securityBasicAuth(ctx context.Context, operationName OperationName, req *http.Request) (context.Context, bool, error) {
 	var t BasicAuth
 	if _, ok := findAuthorization(req.Header, "Basic"); !ok {
@@ -54,15 +80,58 @@ func (s *Server) securityBasicAuth(ctx context.Context, operationName OperationN
 	}
 	return rctx, true, err
 }
+func (s *Server) securityBearerAuth(ctx context.Context, operationName OperationName, req *http.Request) (context.Context, bool, error) {
+	var t BearerAuth
+	token, ok := findAuthorization(req.Header, "Bearer")
+	if !ok {
+		return ctx, false, nil
+	}
+	t.Token = token
+	rctx, err := s.sec.HandleBearerAuth(ctx, operationName, t)
+	if errors.Is(err, ogenerrors.ErrSkipServerSecurity) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return rctx, true, err
+}
+func (s *Server) securityMutualTLS(ctx context.Context, operationName OperationName, req *http.Request) (context.Context, bool, error) {
+	t := req
+	rctx, err := s.sec.HandleMutualTLS(ctx, operationName, t)
+	if errors.Is(err, ogenerrors.ErrSkipServerSecurity) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return rctx, true, err
+}
 
 // SecuritySource is provider of security values (tokens, passwords, etc.).
 type SecuritySource interface {
+	// ApiKeyAuth provides apiKeyAuth security value.
+	// API key issued to a machine client, in the form `fuk_<prefix>_<secret>`.
+	ApiKeyAuth(ctx context.Context, operationName OperationName) (ApiKeyAuth, error)
 	// BasicAuth provides basicAuth security value.
 	// Requires valid username/password combination.
 	// Credentials must be base64 encoded in the Authorization header.
 	BasicAuth(ctx context.Context, operationName OperationName) (BasicAuth, error)
+	// BearerAuth provides bearerAuth security value.
+	// OIDC ID token (e.g. Google or Keycloak) verified against the issuer's JWKS.
+	BearerAuth(ctx context.Context, operationName OperationName) (BearerAuth, error)
+	// MutualTLS provides mutualTLS security value.
+	// Client certificate verified during the TLS handshake. Only used when no
+	// other credentials are sent.
+	MutualTLS(ctx context.Context, operationName OperationName, req *http.Request) error
 }
 
+func (s *Client) securityApiKeyAuth(ctx context.Context, operationName OperationName, req *http.Request) error {
+	t, err := s.sec.ApiKeyAuth(ctx, operationName)
+	if err != nil {
+		return errors.Wrap(err, "security source \"ApiKeyAuth\"")
+	}
+	req.Header.Set("X-API-Key", t.APIKey)
+	return nil
+}
 func (s *Client) securityBasicAuth(ctx context.Context, operationName OperationName, req *http.Request) error {
 	t, err := s.sec.BasicAuth(ctx, operationName)
 	if err != nil {
@@ -71,3 +140,17 @@ func (s *Client) securityBasicAuth(ctx context.Context, operationName OperationN
 	req.SetBasicAuth(t.Username, t.Password)
 	return nil
 }
+func (s *Client) securityBearerAuth(ctx context.Context, operationName OperationName, req *http.Request) error {
+	t, err := s.sec.BearerAuth(ctx, operationName)
+	if err != nil {
+		return errors.Wrap(err, "security source \"BearerAuth\"")
+	}
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+	return nil
+}
+func (s *Client) securityMutualTLS(ctx context.Context, operationName OperationName, req *http.Request) error {
+	if err := s.sec.MutualTLS(ctx, operationName, req); err != nil {
+		return errors.Wrap(err, "security source \"MutualTLS\"")
+	}
+	return nil
+}
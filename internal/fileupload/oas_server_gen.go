@@ -8,6 +8,54 @@ import (
 
 // Handler handles operations described by OpenAPI v3 specification.
 type Handler interface {
+	// DeleteFile implements deleteFile operation.
+	//
+	// Requires the `delete` role for the object.
+	//
+	// DELETE /file
+	DeleteFile(ctx context.Context, params DeleteFileParams) error
+	// DownloadFile implements downloadFile operation.
+	//
+	// Streams an object, decrypting it if it was stored with CSEK or envelope
+	// encryption. Requires the `read` role for the object.
+	//
+	// GET /file
+	DownloadFile(ctx context.Context, params DownloadFileParams) (*DownloadFileOKHeaders, error)
+	// GetLoadStatus implements getLoadStatus operation.
+	//
+	// Returns the state of the BigQuery load job started for an upload, by
+	// the `loadJobId` returned from the upload. Requires the `read` role for
+	// the uploaded object.
+	//
+	// GET /uploads/{id}/load-status
+	GetLoadStatus(ctx context.Context, params GetLoadStatusParams) (*LoadStatus, error)
+	// GetLogLevel implements getLogLevel operation.
+	//
+	// Returns the lowest level the server currently logs. Requires the `admin` role.
+	//
+	// GET /admin/log-level
+	GetLogLevel(ctx context.Context) (*LogLevel, error)
+	// ListFiles implements listFiles operation.
+	//
+	// Lists objects under an optional prefix. Requires the `list` role for
+	// the prefix.
+	//
+	// GET /files
+	ListFiles(ctx context.Context, params ListFilesParams) (*FileList, error)
+	// ListWebhookDeadLetters implements listWebhookDeadLetters operation.
+	//
+	// Lists webhook deliveries that failed on every attempt and will not be
+	// retried. Requires the `admin` role.
+	//
+	// GET /admin/webhooks/dead-letters
+	ListWebhookDeadLetters(ctx context.Context) (*WebhookDeliveryList, error)
+	// SetLogLevel implements setLogLevel operation.
+	//
+	// Changes the lowest level the server logs until it restarts, e.g. to
+	// debug a production issue without a redeploy. Requires the `admin` role.
+	//
+	// PUT /admin/log-level
+	SetLogLevel(ctx context.Context, req *LogLevel) (*LogLevel, error)
 	// UploadFile implements uploadFile operation.
 	//
 	// Uploads a spreadsheet file to GCS bucket with the following constraints:
@@ -44,3 +92,36 @@ func NewServer(h Handler, sec SecurityHandler, opts ...ServerOption) (*Server, e
 		baseServer: s,
 	}, nil
 }
+
+// WebhookHandler handles webhooks described by OpenAPI v3 specification.
+type WebhookHandler interface {
+	// UploadCompleted implements uploadCompleted operation.
+	//
+	// Sent to each subscription after a successful upload. The request
+	// carries `X-Webhook-Signature: t=<unix time>,v1=<signature>`, where the
+	// signature is the hex HMAC-SHA256 of `<unix time>.<body>` keyed with
+	// the subscription secret. Any 2xx response acknowledges the event;
+	// anything else is retried with exponential backoff. Retries reuse the
+	// event `id`, so receivers can drop duplicates.
+	//
+	UploadCompleted(ctx context.Context, req *UploadEvent) (*UploadCompleted2XX, error)
+}
+
+// WebhookServer implements http server based on OpenAPI v3 specification and
+// calls WebhookHandler to handle requests.
+type WebhookServer struct {
+	h WebhookHandler
+	baseServer
+}
+
+// NewWebhookServer creates new WebhookServer.
+func NewWebhookServer(h WebhookHandler, opts ...ServerOption) (*WebhookServer, error) {
+	s, err := newServerConfig(opts...).baseServer()
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookServer{
+		h:          h,
+		baseServer: s,
+	}, nil
+}
@@ -13,6 +13,75 @@ type UnimplementedHandler struct{}
 
 var _ Handler = UnimplementedHandler{}
 
+// DeleteFile implements deleteFile operation.
+//
+// Requires the `delete` role for the object.
+//
+// DELETE /file
+func (UnimplementedHandler) DeleteFile(ctx context.Context, params DeleteFileParams) error {
+	return ht.ErrNotImplemented
+}
+
+// DownloadFile implements downloadFile operation.
+//
+// Streams an object, decrypting it if it was stored with CSEK or envelope
+// encryption. Requires the `read` role for the object.
+//
+// GET /file
+func (UnimplementedHandler) DownloadFile(ctx context.Context, params DownloadFileParams) (r *DownloadFileOKHeaders, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// GetLoadStatus implements getLoadStatus operation.
+//
+// Returns the state of the BigQuery load job started for an upload, by
+// the `loadJobId` returned from the upload. Requires the `read` role for
+// the uploaded object.
+//
+// GET /uploads/{id}/load-status
+func (UnimplementedHandler) GetLoadStatus(ctx context.Context, params GetLoadStatusParams) (r *LoadStatus, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// GetLogLevel implements getLogLevel operation.
+//
+// Returns the lowest level the server currently logs. Requires the `admin` role.
+//
+// GET /admin/log-level
+func (UnimplementedHandler) GetLogLevel(ctx context.Context) (r *LogLevel, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ListFiles implements listFiles operation.
+//
+// Lists objects under an optional prefix. Requires the `list` role for
+// the prefix.
+//
+// GET /files
+func (UnimplementedHandler) ListFiles(ctx context.Context, params ListFilesParams) (r *FileList, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ListWebhookDeadLetters implements listWebhookDeadLetters operation.
+//
+// Lists webhook deliveries that failed on every attempt and will not be
+// retried. Requires the `admin` role.
+//
+// GET /admin/webhooks/dead-letters
+func (UnimplementedHandler) ListWebhookDeadLetters(ctx context.Context) (r *WebhookDeliveryList, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// SetLogLevel implements setLogLevel operation.
+//
+// Changes the lowest level the server logs until it restarts, e.g. to
+// debug a production issue without a redeploy. Requires the `admin` role.
+//
+// PUT /admin/log-level
+func (UnimplementedHandler) SetLogLevel(ctx context.Context, req *LogLevel) (r *LogLevel, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // UploadFile implements uploadFile operation.
 //
 // Uploads a spreadsheet file to GCS bucket with the following constraints:
@@ -33,3 +102,17 @@ func (UnimplementedHandler) NewError(ctx context.Context, err error) (r *ErrorSt
 	r = new(ErrorStatusCodeWithHeaders)
 	return r
 }
+
+var _ WebhookHandler = UnimplementedHandler{}
+
+// UploadCompleted implements uploadCompleted operation.
+//
+// Sent to each subscription after a successful upload. The request
+// carries `X-Webhook-Signature: t=<unix time>,v1=<signature>`, where the
+// signature is the hex HMAC-SHA256 of `<unix time>.<body>` keyed with
+// the subscription secret. Any 2xx response acknowledges the event;
+// anything else is retried with exponential backoff. Retries reuse the
+// event `id`, so receivers can drop duplicates.
+func (UnimplementedHandler) UploadCompleted(ctx context.Context, req *UploadEvent) (r *UploadCompleted2XX, _ error) {
+	return r, ht.ErrNotImplemented
+}
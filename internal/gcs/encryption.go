@@ -0,0 +1,300 @@
+package gcs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+// EncryptionMode selects how object payloads are protected at rest.
+type EncryptionMode string
+
+const (
+	// EncryptionNone relies on Google-managed default encryption.
+	EncryptionNone EncryptionMode = ""
+	// EncryptionCSEK uses a customer-supplied AES-256 key passed with every request.
+	EncryptionCSEK EncryptionMode = "csek"
+	// EncryptionCMEK uses a customer-managed Cloud KMS key configured on the writer.
+	EncryptionCMEK EncryptionMode = "cmek"
+	// EncryptionEnvelope encrypts the payload in-process before it is written,
+	// so it works against any storage backend.
+	EncryptionEnvelope EncryptionMode = "envelope"
+)
+
+// Object metadata keys describing how an object was encrypted.
+const (
+	MetadataEncryptionMode  = "encryption-mode"
+	MetadataEncryptionKeyID = "encryption-key-id"
+	MetadataWrappedKey      = "encryption-wrapped-key"
+	MetadataEncryptionAlg   = "encryption-algorithm"
+)
+
+const envelopeAlgorithm = "AES256-GCM"
+
+var ErrEncryptionConfig = errors.New("invalid encryption config")
+
+// EncryptionRule describes how objects whose name starts with Prefix are encrypted.
+// An empty prefix matches every object.
+type EncryptionRule struct {
+	Prefix     string         `yaml:"prefix"`
+	Mode       EncryptionMode `yaml:"mode"`
+	KeyID      string         `yaml:"keyId"`
+	KeyFile    string         `yaml:"keyFile"`
+	KMSKeyName string         `yaml:"kmsKeyName"`
+
+	key []byte
+}
+
+// EncryptionConfig holds the deployment default and any per-prefix overrides.
+type EncryptionConfig struct {
+	Default EncryptionRule
+	Rules   []EncryptionRule
+}
+
+type encryptionRulesFile struct {
+	Rules []EncryptionRule `yaml:"rules"`
+}
+
+// LoadEncryptionConfig validates the default rule, reads the optional
+// per-prefix rules file and loads all referenced key material. Rule prefixes
+// are folders, see credentials.FolderPrefix.
+func LoadEncryptionConfig(def EncryptionRule, rulesFile string) (EncryptionConfig, error) {
+	def.Prefix = ""
+	if err := def.load(); err != nil {
+		return EncryptionConfig{}, fmt.Errorf("default rule: %w", err)
+	}
+
+	cfg := EncryptionConfig{Default: def}
+	if strings.TrimSpace(rulesFile) == "" {
+		return cfg, nil
+	}
+
+	raw, err := os.ReadFile(rulesFile)
+	if err != nil {
+		return EncryptionConfig{}, fmt.Errorf("reading encryption rules: %w", err)
+	}
+
+	var parsed encryptionRulesFile
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return EncryptionConfig{}, fmt.Errorf("parsing encryption rules: %w", err)
+	}
+
+	for i, rule := range parsed.Rules {
+		if rule.Prefix == "" {
+			return EncryptionConfig{}, fmt.Errorf("%w: rule %d has no prefix", ErrEncryptionConfig, i)
+		}
+		rule.Prefix = credentials.FolderPrefix(rule.Prefix)
+		if err := rule.load(); err != nil {
+			return EncryptionConfig{}, fmt.Errorf("rule %q: %w", rule.Prefix, err)
+		}
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+
+	return cfg, nil
+}
+
+// ReadableByGoogle reports whether Google services such as BigQuery can read
+// objects encrypted in this mode. CSEK and envelope objects need keys only
+// this service holds.
+func (m EncryptionMode) ReadableByGoogle() bool {
+	return m != EncryptionCSEK && m != EncryptionEnvelope
+}
+
+// ModeFor returns the encryption mode of objects stored as objectName.
+func (c EncryptionConfig) ModeFor(objectName string) EncryptionMode {
+	return c.ruleFor(objectName).Mode
+}
+
+// ruleFor returns the rule with the longest prefix matching the object name.
+func (c EncryptionConfig) ruleFor(objectName string) EncryptionRule {
+	best := c.Default
+	for _, rule := range c.Rules {
+		if strings.HasPrefix(objectName, rule.Prefix) && len(rule.Prefix) > len(best.Prefix) {
+			best = rule
+		}
+	}
+	return best
+}
+
+func (r *EncryptionRule) load() error {
+	r.Mode = EncryptionMode(strings.ToLower(strings.TrimSpace(string(r.Mode))))
+
+	switch r.Mode {
+	case EncryptionNone:
+		return nil
+	case EncryptionCMEK:
+		if r.KMSKeyName == "" {
+			return fmt.Errorf("%w: cmek requires a KMS key name", ErrEncryptionConfig)
+		}
+		if r.KeyID == "" {
+			r.KeyID = r.KMSKeyName
+		}
+		return nil
+	case EncryptionCSEK, EncryptionEnvelope:
+		if r.KeyFile == "" {
+			return fmt.Errorf("%w: %s requires a key file", ErrEncryptionConfig, r.Mode)
+		}
+		key, err := readKeyFile(r.KeyFile)
+		if err != nil {
+			return err
+		}
+		r.key = key
+		if r.KeyID == "" {
+			r.KeyID = keyFingerprint(key)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrEncryptionConfig, r.Mode)
+	}
+}
+
+// readKeyFile accepts either 32 raw bytes or a base64 encoded 32 byte key.
+func readKeyFile(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key file: %w", err)
+	}
+
+	if len(raw) == 32 {
+		return raw, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("%w: key file %s must contain a 32 byte AES-256 key", ErrEncryptionConfig, path)
+	}
+	return decoded, nil
+}
+
+func keyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// apply keys the object handle for the rule and returns the payload and object
+// metadata to write. The payload differs from the input only in envelope mode.
+func (r EncryptionRule) apply(obj *storage.ObjectHandle, payload []byte) (*storage.ObjectHandle, []byte, map[string]string, error) {
+	if r.Mode == EncryptionNone {
+		return obj, payload, nil, nil
+	}
+
+	metadata := map[string]string{
+		MetadataEncryptionMode:  string(r.Mode),
+		MetadataEncryptionKeyID: r.KeyID,
+	}
+
+	switch r.Mode {
+	case EncryptionCSEK:
+		obj = obj.Key(r.key)
+	case EncryptionEnvelope:
+		sealed, wrappedKey, err := sealEnvelope(r.key, payload)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		payload = sealed
+		metadata[MetadataWrappedKey] = wrappedKey
+		metadata[MetadataEncryptionAlg] = envelopeAlgorithm
+	}
+
+	return obj, payload, metadata, nil
+}
+
+// sealEnvelope encrypts payload with a fresh data key and wraps that data key
+// with the key-encryption key. Both ciphertexts are nonce-prefixed AES-GCM.
+func sealEnvelope(kek, payload []byte) ([]byte, string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, "", fmt.Errorf("generating data key: %w", err)
+	}
+
+	sealed, err := gcmSeal(dataKey, payload)
+	if err != nil {
+		return nil, "", err
+	}
+
+	wrapped, err := gcmSeal(kek, dataKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return sealed, base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// OpenEnvelope reverses envelope encryption, locating the key-encryption key
+// by the key id recorded in the object's metadata.
+func (c EncryptionConfig) OpenEnvelope(metadata map[string]string, sealed []byte) ([]byte, error) {
+	kek, err := c.keyByID(EncryptionEnvelope, metadata[MetadataEncryptionKeyID])
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(metadata[MetadataWrappedKey])
+	if err != nil {
+		return nil, fmt.Errorf("decoding wrapped key: %w", err)
+	}
+
+	dataKey, err := gcmOpen(kek, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping data key: %w", err)
+	}
+
+	return gcmOpen(dataKey, sealed)
+}
+
+// keyByID finds the key of a configured rule, so objects written under a
+// rule can be read back for as long as their key stays configured.
+func (c EncryptionConfig) keyByID(mode EncryptionMode, keyID string) ([]byte, error) {
+	for _, rule := range append([]EncryptionRule{c.Default}, c.Rules...) {
+		if rule.Mode == mode && rule.KeyID == keyID {
+			return rule.key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no %s key with id %q", ErrEncryptionConfig, mode, keyID)
+}
+
+func gcmSeal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func gcmOpen(key, sealed []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
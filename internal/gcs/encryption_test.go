@@ -0,0 +1,110 @@
+package gcs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyFile(t *testing.T, key []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)), 0o600))
+	return path
+}
+
+func TestLoadEncryptionConfigSelectsLongestPrefix(t *testing.T) {
+	keyFile := writeKeyFile(t, bytes.Repeat([]byte{1}, 32))
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesFile, []byte(`
+rules:
+  - prefix: cards/
+    mode: cmek
+    kmsKeyName: projects/p/locations/l/keyRings/r/cryptoKeys/cards
+  - prefix: cards/holders/
+    mode: csek
+    keyFile: `+keyFile+`
+`), 0o600))
+
+	cfg, err := LoadEncryptionConfig(EncryptionRule{}, rulesFile)
+	require.NoError(t, err)
+
+	require.Equal(t, EncryptionNone, cfg.ruleFor("report.csv").Mode)
+	require.Equal(t, EncryptionCMEK, cfg.ruleFor("cards/2026.csv").Mode)
+	require.Equal(t, "projects/p/locations/l/keyRings/r/cryptoKeys/cards", cfg.ruleFor("cards/2026.csv").KeyID)
+	require.Equal(t, EncryptionCSEK, cfg.ruleFor("cards/holders/data.csv").Mode)
+	require.NotEmpty(t, cfg.ruleFor("cards/holders/data.csv").KeyID)
+}
+
+func TestLoadEncryptionConfigTreatsPrefixesAsFolders(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesFile, []byte(`
+rules:
+  - prefix: partner-a
+    mode: cmek
+    kmsKeyName: projects/p/locations/l/keyRings/r/cryptoKeys/partner-a
+`), 0o600))
+
+	cfg, err := LoadEncryptionConfig(EncryptionRule{}, rulesFile)
+	require.NoError(t, err)
+
+	require.Equal(t, "partner-a/", cfg.Rules[0].Prefix)
+	require.Equal(t, EncryptionCMEK, cfg.ModeFor("partner-a/report.csv"))
+	require.Equal(t, EncryptionNone, cfg.ModeFor("partner-ab/report.csv"))
+}
+
+func TestLoadEncryptionConfigRejectsMissingKey(t *testing.T) {
+	_, err := LoadEncryptionConfig(EncryptionRule{Mode: EncryptionCSEK}, "")
+	require.ErrorIs(t, err, ErrEncryptionConfig)
+
+	_, err = LoadEncryptionConfig(EncryptionRule{Mode: EncryptionCMEK}, "")
+	require.ErrorIs(t, err, ErrEncryptionConfig)
+
+	_, err = LoadEncryptionConfig(EncryptionRule{Mode: "rot13"}, "")
+	require.ErrorIs(t, err, ErrEncryptionConfig)
+}
+
+func TestReadKeyFileRejectsShortKey(t *testing.T) {
+	_, err := readKeyFile(writeKeyFile(t, []byte("too-short")))
+	require.ErrorIs(t, err, ErrEncryptionConfig)
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	cfg, err := LoadEncryptionConfig(EncryptionRule{
+		Mode:    EncryptionEnvelope,
+		KeyFile: writeKeyFile(t, bytes.Repeat([]byte{7}, 32)),
+	}, "")
+	require.NoError(t, err)
+
+	payload := []byte("pan,expiry\n4111111111111111,12/30\n")
+	_, sealed, metadata, err := cfg.ruleFor("cards.csv").apply(nil, payload)
+	require.NoError(t, err)
+	require.NotEqual(t, payload, sealed)
+	require.Equal(t, string(EncryptionEnvelope), metadata[MetadataEncryptionMode])
+	require.Equal(t, cfg.Default.KeyID, metadata[MetadataEncryptionKeyID])
+	require.NotEmpty(t, metadata[MetadataWrappedKey])
+
+	opened, err := cfg.OpenEnvelope(metadata, sealed)
+	require.NoError(t, err)
+	require.Equal(t, payload, opened)
+}
+
+func TestOpenEnvelopeRejectsUnknownKeyID(t *testing.T) {
+	_, err := EncryptionConfig{}.OpenEnvelope(map[string]string{MetadataEncryptionKeyID: "missing"}, []byte("x"))
+	require.ErrorIs(t, err, ErrEncryptionConfig)
+}
+
+func TestModeForReadableByGoogle(t *testing.T) {
+	cfg := EncryptionConfig{
+		Default: EncryptionRule{Mode: EncryptionCMEK},
+		Rules:   []EncryptionRule{{Prefix: "secret/", Mode: EncryptionEnvelope}},
+	}
+
+	require.True(t, cfg.ModeFor("public/a.csv").ReadableByGoogle())
+	require.False(t, cfg.ModeFor("secret/a.csv").ReadableByGoogle())
+	require.False(t, EncryptionCSEK.ReadableByGoogle())
+}
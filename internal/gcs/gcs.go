@@ -3,7 +3,9 @@ package gcs
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +18,8 @@ import (
 	"cloud.google.com/go/storage"
 	ogenhttp "github.com/ogen-go/ogen/http"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/pii"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const defaultMaxUploadSizeBytes int64 = 10 * 1024 * 1024 // 10MB
@@ -31,6 +35,8 @@ type GcsConfig struct {
 	GcsLocation        string
 	GcsBucketName      string
 	MaxUploadSizeBytes int64
+	Encryption         EncryptionConfig
+	PII                *pii.Scanner
 }
 
 type GcsClient struct {
@@ -55,54 +61,131 @@ func (c GcsConfig) maxUploadSize() int64 {
 	return defaultMaxUploadSizeBytes
 }
 
-// UploadToGcs handles file uploads to Google Cloud Storage
-func (g *GcsClient) UploadToGcs(ctx context.Context, filename string, file ogenhttp.MultipartFile) (*fileupload.UploadResponse, error) {
-	filename = sanitizeFilename(filename)
+// UploadToGcs handles file uploads to Google Cloud Storage.
+// The filename may include a folder prefix, see ObjectName.
+func (g *GcsClient) UploadToGcs(ctx context.Context, filename string, file ogenhttp.MultipartFile) (_ *fileupload.UploadResponse, err error) {
+	start := time.Now()
+	contentType := "unknown"
+	var size int64
+
+	filename = sanitizeObjectName(filename)
+	ctx, span := startSpan(ctx, "gcs.Upload",
+		attrFilename.String(filename),
+		attrBucket.String(g.GcsConfig.GcsBucketName),
+	)
+	defer func() {
+		span.SetAttributes(attrFileSize.Int64(size), attrContentType.String(contentType))
+		endSpan(span, err)
+		metrics().recordUpload(ctx, contentType, size, time.Since(start), err)
+	}()
+
 	if filename == "" {
 		return nil, fmt.Errorf("%w: empty filename", ErrInvalidFile)
 	}
 
+	_, readSpan := startSpan(ctx, "gcs.ReadFile", attribute.Int64("file.declared_size", file.Size))
 	fileBytes, err := readFileWithLimit(file.File, file.Size, g.GcsConfig.maxUploadSize())
+	readSpan.SetAttributes(attrFileSize.Int64(int64(len(fileBytes))))
+	endSpan(readSpan, err)
 	if err != nil {
-		g.Logger.Error("file failed validation", "filename", filename, "error", err)
+		g.Logger.ErrorContext(ctx, "file failed validation", "filename", filename, "error", err)
 		return nil, err
 	}
 
-	contentType, err := detectContentType(filename, fileBytes)
+	size = int64(len(fileBytes))
+
+	_, detectSpan := startSpan(ctx, "gcs.DetectContentType")
+	detected, err := detectContentType(filename, fileBytes)
+	detectSpan.SetAttributes(attrContentType.String(detected))
+	endSpan(detectSpan, err)
 	if err != nil {
-		g.Logger.Error("content type detection failed", "filename", filename, "error", err)
+		g.Logger.ErrorContext(ctx, "content type detection failed", "filename", filename, "error", err)
 		return nil, err
 	}
+	contentType = detected
+
+	var sensitiveColumns []pii.Column
+	if contentType == "text/csv" {
+		fileBytes, sensitiveColumns, err = g.GcsConfig.PII.ScanCSV(fileBytes)
+		if err != nil {
+			if !errors.Is(err, pii.ErrSensitiveData) {
+				err = fmt.Errorf("%w: %v", ErrInvalidFileType, err)
+			}
+			g.Logger.WarnContext(ctx, "sensitive data scan failed", "filename", filename, "error", err)
+			return nil, err
+		}
+		if len(sensitiveColumns) > 0 {
+			g.Logger.InfoContext(ctx, "sensitive data redacted", "filename", filename, "columns", len(sensitiveColumns), "action", g.GcsConfig.PII.Action())
+		}
+	}
+
+	// Describe the object as stored: after redaction, before encryption.
+	size = int64(len(fileBytes))
+	checksum := sha256.Sum256(fileBytes)
 
-	obj := g.GcsClient.Bucket(g.GcsConfig.GcsBucketName).Object(filename)
-	size, err := uploadWithRetry(fileBytes, func(reader io.Reader) error {
+	encryption := g.GcsConfig.Encryption.ruleFor(filename)
+	obj, payload, metadata, err := encryption.apply(g.GcsClient.Bucket(g.GcsConfig.GcsBucketName).Object(filename), fileBytes)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting %s: %w", filename, err)
+	}
+
+	_, err = uploadWithRetry(ctx, payload, func(ctx context.Context, reader io.Reader) error {
 		w := obj.NewWriter(ctx)
 		w.ContentType = contentType
+		w.Metadata = metadata
+		if encryption.Mode == EncryptionCMEK {
+			w.KMSKeyName = encryption.KMSKeyName
+		}
 
 		if _, copyErr := io.Copy(w, reader); copyErr != nil {
 			_ = w.Close()
 			return copyErr
 		}
 
-		if closeErr := w.Close(); closeErr != nil {
-			return closeErr
-		}
-
-		return nil
+		// Close flushes the final chunk and commits the object, so it is
+		// where most of the time and most GCS errors show up.
+		_, closeSpan := startSpan(ctx, "gcs.WriterClose")
+		closeErr := w.Close()
+		endSpan(closeSpan, closeErr)
+		return closeErr
 	})
 	if err != nil {
 		return nil, fmt.Errorf("gcs upload failed for %s: %w", filename, err)
 	}
 
 	return &fileupload.UploadResponse{
-		Filename:   filename,
-		Bucket:     g.GcsConfig.GcsBucketName,
-		Gcspath:    fmt.Sprintf("gs://%s/%s", g.GcsConfig.GcsBucketName, filename),
-		FileSize:   size,
-		UploadTime: time.Now().UTC(),
+		Filename:         filename,
+		Bucket:           g.GcsConfig.GcsBucketName,
+		Gcspath:          fmt.Sprintf("gs://%s/%s", g.GcsConfig.GcsBucketName, filename),
+		FileSize:         size,
+		SHA256:           fileupload.NewOptString(hex.EncodeToString(checksum[:])),
+		UploadTime:       time.Now().UTC(),
+		SensitiveColumns: toSensitiveColumns(sensitiveColumns, g.GcsConfig.PII.Action()),
 	}, nil
 }
 
+func toSensitiveColumns(columns []pii.Column, action pii.Action) []fileupload.SensitiveColumn {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	out := make([]fileupload.SensitiveColumn, 0, len(columns))
+	for _, c := range columns {
+		kinds := make([]fileupload.SensitiveColumnKindsItem, 0, len(c.Kinds))
+		for _, k := range c.Kinds {
+			kinds = append(kinds, fileupload.SensitiveColumnKindsItem(k))
+		}
+		out = append(out, fileupload.SensitiveColumn{
+			Index:  c.Index,
+			Name:   c.Name,
+			Kinds:  kinds,
+			Cells:  c.Cells,
+			Action: fileupload.SensitiveColumnAction(action),
+		})
+	}
+	return out
+}
+
 func readFileWithLimit(reader io.Reader, declaredSize int64, maxSize int64) ([]byte, error) {
 	if reader == nil {
 		return nil, fmt.Errorf("%w: nil file reader", ErrInvalidFile)
@@ -164,26 +247,59 @@ func looksLikeCSV(sniff []byte) bool {
 	return err == nil || errors.Is(err, io.EOF)
 }
 
-// uploadWithRetry retries upload with a fresh reader each time.
-func uploadWithRetry(payload []byte, uploadFn func(io.Reader) error) (int64, error) {
+// uploadWithRetry retries upload with a fresh reader each time. Each attempt
+// runs in its own span, passed to uploadFn through ctx.
+func uploadWithRetry(ctx context.Context, payload []byte, uploadFn func(context.Context, io.Reader) error) (int64, error) {
 	const maxAttempts = 3
 	backoff := []time.Duration{100 * time.Millisecond, 500 * time.Millisecond}
 
 	var err error
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		err = uploadFn(bytes.NewReader(payload))
+		attemptCtx, span := startSpan(ctx, "gcs.UploadAttempt",
+			attrAttempt.Int(attempt),
+			attrFileSize.Int(len(payload)),
+		)
+		err = uploadFn(attemptCtx, bytes.NewReader(payload))
+		endSpan(span, err)
 		if err == nil {
 			return int64(len(payload)), nil
 		}
 
 		if attempt < maxAttempts {
 			time.Sleep(backoff[attempt-1])
+			metrics().recordRetry(ctx)
 		}
 	}
 
 	return 0, fmt.Errorf("upload failed after %d attempts: %w", maxAttempts, err)
 }
 
+// ObjectName joins an optional folder prefix and a filename into a sanitized
+// object name. Slashes in the filename itself are not treated as folders.
+func ObjectName(prefix, filename string) (string, error) {
+	name := sanitizeFilename(filename)
+	if name == "" {
+		return "", fmt.Errorf("%w: empty filename", ErrInvalidFile)
+	}
+
+	if folder := sanitizeObjectName(prefix); folder != "" {
+		name = folder + "/" + name
+	}
+	return name, nil
+}
+
+// sanitizeObjectName sanitizes each slash separated segment and drops empty ones.
+func sanitizeObjectName(name string) string {
+	segments := strings.Split(name, "/")
+	clean := segments[:0]
+	for _, segment := range segments {
+		if segment = sanitizeFilename(segment); segment != "" && segment != "." {
+			clean = append(clean, segment)
+		}
+	}
+	return strings.Join(clean, "/")
+}
+
 // sanitizeFilename ensures safe filenames
 func sanitizeFilename(name string) string {
 	name = strings.ReplaceAll(name, "..", "")
@@ -1,6 +1,7 @@
 package gcs
 
 import (
+	"context"
 	"errors"
 	"io"
 	"strings"
@@ -43,7 +44,7 @@ func TestUploadWithRetryRetriesWithFreshReader(t *testing.T) {
 	payload := []byte("test-payload")
 	calls := 0
 
-	size, err := uploadWithRetry(payload, func(r io.Reader) error {
+	size, err := uploadWithRetry(context.Background(), payload, func(ctx context.Context, r io.Reader) error {
 		calls++
 		b, readErr := io.ReadAll(r)
 		require.NoError(t, readErr)
@@ -63,7 +64,7 @@ func TestUploadWithRetryRetriesWithFreshReader(t *testing.T) {
 func TestUploadWithRetryFailsAfterMaxAttempts(t *testing.T) {
 	calls := 0
 
-	_, err := uploadWithRetry([]byte("x"), func(r io.Reader) error {
+	_, err := uploadWithRetry(context.Background(), []byte("x"), func(ctx context.Context, r io.Reader) error {
 		calls++
 		return errors.New("always fails")
 	})
@@ -71,3 +72,20 @@ func TestUploadWithRetryFailsAfterMaxAttempts(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, 3, calls)
 }
+
+func TestObjectNameJoinsSanitizedPrefix(t *testing.T) {
+	name, err := ObjectName("/partner-a//2024 q1/", "report.csv")
+	require.NoError(t, err)
+	require.Equal(t, "partner-a/2024_q1/report.csv", name)
+
+	name, err = ObjectName("", "../a/b.csv")
+	require.NoError(t, err)
+	require.Equal(t, "_a_b.csv", name)
+
+	name, err = ObjectName("../../etc", "x.csv")
+	require.NoError(t, err)
+	require.Equal(t, "etc/x.csv", name)
+
+	_, err = ObjectName("partner-a", "")
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
@@ -0,0 +1,265 @@
+// Package gcstest provides an in-memory fake of the parts of the GCS JSON API
+// the service uses, for tests that need a real *storage.Client.
+package gcstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// Object is a stored object.
+type Object struct {
+	Name        string
+	ContentType string
+	Metadata    map[string]string
+	Data        []byte
+	Updated     time.Time
+}
+
+// Bucket holds the bucket attributes the fake reports.
+type Bucket struct {
+	RetentionPeriod time.Duration
+	RetentionLocked bool
+}
+
+// Server serves objects from memory. Buckets exist implicitly, with the
+// attributes set by SetBucket.
+type Server struct {
+	srv *httptest.Server
+
+	mu      sync.Mutex
+	buckets map[string]Bucket
+	objects map[string]map[string]Object
+}
+
+func NewServer() *Server {
+	s := &Server{buckets: map[string]Bucket{}, objects: map[string]map[string]Object{}}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a storage client talking to the fake.
+func (s *Server) Client(ctx context.Context) (*storage.Client, error) {
+	return storage.NewClient(ctx,
+		option.WithEndpoint(s.srv.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+		storage.WithJSONReads(),
+	)
+}
+
+// SetBucket sets the attributes of a bucket.
+func (s *Server) SetBucket(name string, b Bucket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets[name] = b
+}
+
+// Put stores an object directly.
+func (s *Server) Put(bucket string, obj Object) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if obj.Updated.IsZero() {
+		obj.Updated = time.Now().UTC()
+	}
+	if s.objects[bucket] == nil {
+		s.objects[bucket] = map[string]Object{}
+	}
+	s.objects[bucket][obj.Name] = obj
+}
+
+// Get returns a stored object.
+func (s *Server) Get(bucket, name string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[bucket][name]
+	return obj, ok
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	upload := strings.HasPrefix(path, "/upload/storage/v1/b/")
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/upload"), "/storage/v1/b/")
+
+	parts := strings.SplitN(path, "/", 3)
+	bucket, _ := url.PathUnescape(parts[0])
+	switch {
+	case !upload && len(parts) == 1 && r.Method == http.MethodGet:
+		s.bucket(w, bucket)
+	case upload && r.Method == http.MethodPost:
+		s.insert(w, r, bucket)
+	case len(parts) == 2 && parts[1] == "o" && r.Method == http.MethodGet:
+		s.list(w, r, bucket)
+	case len(parts) == 3 && parts[1] == "o":
+		name, _ := url.PathUnescape(parts[2])
+		switch r.Method {
+		case http.MethodGet:
+			s.get(w, r, bucket, name)
+		case http.MethodDelete:
+			s.delete(w, bucket, name)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "unsupported method")
+		}
+	default:
+		writeError(w, http.StatusNotImplemented, "not implemented by gcstest: "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (s *Server) insert(w http.ResponseWriter, r *http.Request, bucket string) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || r.URL.Query().Get("uploadType") != "multipart" {
+		writeError(w, http.StatusBadRequest, "only multipart uploads are supported")
+		return
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+
+	var attrs struct {
+		Name        string            `json:"name"`
+		ContentType string            `json:"contentType"`
+		Metadata    map[string]string `json:"metadata"`
+	}
+	part, err := mr.NextPart()
+	if err == nil {
+		err = json.NewDecoder(part).Decode(&attrs)
+	}
+	var data []byte
+	if err == nil {
+		part, err = mr.NextPart()
+	}
+	if err == nil {
+		data, err = io.ReadAll(part)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if attrs.Name == "" {
+		attrs.Name = r.URL.Query().Get("name")
+	}
+
+	if r.URL.Query().Get("ifGenerationMatch") == "0" {
+		if _, exists := s.Get(bucket, attrs.Name); exists {
+			writeError(w, http.StatusPreconditionFailed, "object exists")
+			return
+		}
+	}
+
+	obj := Object{Name: attrs.Name, ContentType: attrs.ContentType, Metadata: attrs.Metadata, Data: data}
+	s.Put(bucket, obj)
+	obj, _ = s.Get(bucket, attrs.Name)
+	writeJSON(w, resource(bucket, obj))
+}
+
+func (s *Server) bucket(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	b := s.buckets[name]
+	s.mu.Unlock()
+
+	res := map[string]any{"kind": "storage#bucket", "name": name, "id": name}
+	if b.RetentionPeriod > 0 {
+		res["retentionPolicy"] = map[string]any{
+			"retentionPeriod": strconv.FormatInt(int64(b.RetentionPeriod.Seconds()), 10),
+			"isLocked":        b.RetentionLocked,
+			"effectiveTime":   time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+	writeJSON(w, res)
+}
+
+// list pages through names in order; the page token is the next name.
+func (s *Server) list(w http.ResponseWriter, r *http.Request, bucket string) {
+	q := r.URL.Query()
+	prefix, token := q.Get("prefix"), q.Get("pageToken")
+	maxResults, _ := strconv.Atoi(q.Get("maxResults"))
+	if maxResults <= 0 {
+		maxResults = 1000
+	}
+
+	s.mu.Lock()
+	names := make([]string, 0, len(s.objects[bucket]))
+	for name := range s.objects[bucket] {
+		if strings.HasPrefix(name, prefix) && name >= token {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	items := []map[string]any{}
+	for _, name := range names[:min(len(names), maxResults)] {
+		items = append(items, resource(bucket, s.objects[bucket][name]))
+	}
+	s.mu.Unlock()
+
+	res := map[string]any{"kind": "storage#objects", "items": items}
+	if len(names) > maxResults {
+		res["nextPageToken"] = names[maxResults]
+	}
+	writeJSON(w, res)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, bucket, name string) {
+	obj, ok := s.Get(bucket, name)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no such object")
+		return
+	}
+	if r.URL.Query().Get("alt") == "media" {
+		w.Header().Set("Content-Type", obj.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.Data)))
+		_, _ = w.Write(obj.Data)
+		return
+	}
+	writeJSON(w, resource(bucket, obj))
+}
+
+func (s *Server) delete(w http.ResponseWriter, bucket, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[bucket][name]; !ok {
+		writeError(w, http.StatusNotFound, "no such object")
+		return
+	}
+	delete(s.objects[bucket], name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func resource(bucket string, obj Object) map[string]any {
+	return map[string]any{
+		"kind":        "storage#object",
+		"bucket":      bucket,
+		"name":        obj.Name,
+		"contentType": obj.ContentType,
+		"metadata":    obj.Metadata,
+		"size":        strconv.Itoa(len(obj.Data)),
+		"updated":     obj.Updated.Format(time.RFC3339Nano),
+		"generation":  "1",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintf(w, `{"error":{"code":%d,"message":%q}}`, code, message)
+}
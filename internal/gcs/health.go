@@ -0,0 +1,37 @@
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// requiredPermissions are needed to upload and read back objects.
+var requiredPermissions = []string{
+	"storage.objects.create",
+	"storage.objects.get",
+}
+
+var ErrMissingPermissions = errors.New("missing bucket permissions")
+
+// CheckBucket verifies the bucket is reachable and the service account may
+// write to it. It uses testIamPermissions, which needs no extra role.
+func (g *GcsClient) CheckBucket(ctx context.Context) error {
+	granted, err := g.GcsClient.Bucket(g.GcsConfig.GcsBucketName).IAM().TestPermissions(ctx, requiredPermissions)
+	if err != nil {
+		return fmt.Errorf("checking bucket %s: %w", g.GcsConfig.GcsBucketName, err)
+	}
+
+	var missing []string
+	for _, p := range requiredPermissions {
+		if !slices.Contains(granted, p) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w on %s: %s", ErrMissingPermissions, g.GcsConfig.GcsBucketName, strings.Join(missing, ", "))
+	}
+	return nil
+}
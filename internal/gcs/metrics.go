@@ -0,0 +1,104 @@
+package gcs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/pii"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName = "gitlab.com/totalprocessing/file-upload/internal/gcs"
+
+// Upload outcomes recorded on upload.duration.
+const (
+	outcomeSuccess  = "success"
+	outcomeRejected = "rejected"
+	outcomeError    = "error"
+)
+
+type uploadMetrics struct {
+	size       metric.Int64Histogram
+	duration   metric.Float64Histogram
+	rejections metric.Int64Counter
+	retries    metric.Int64Counter
+}
+
+// metrics creates the instruments once from the global meter provider, which
+// forwards to the provider set up by observability.New.
+var metrics = sync.OnceValue(func() *uploadMetrics {
+	meter := otel.Meter(instrumentationName)
+	m := &uploadMetrics{}
+	var err error
+
+	m.size, err = meter.Int64Histogram("upload.size",
+		metric.WithDescription("Size of uploaded files"),
+		metric.WithUnit("By"),
+		metric.WithExplicitBucketBoundaries(1<<10, 10<<10, 100<<10, 1<<20, 5<<20, 10<<20, 50<<20, 100<<20),
+	)
+	otel.Handle(err)
+
+	m.duration, err = meter.Float64Histogram("upload.duration",
+		metric.WithDescription("Time to validate and store an upload"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60),
+	)
+	otel.Handle(err)
+
+	m.rejections, err = meter.Int64Counter("upload.rejections",
+		metric.WithDescription("Uploads rejected by validation, by reason"),
+	)
+	otel.Handle(err)
+
+	m.retries, err = meter.Int64Counter("upload.retries",
+		metric.WithDescription("Retried GCS write attempts"),
+	)
+	otel.Handle(err)
+
+	return m
+})
+
+func (m *uploadMetrics) recordUpload(ctx context.Context, contentType string, size int64, elapsed time.Duration, err error) {
+	outcome := outcomeSuccess
+	reason := rejectionReason(err)
+	switch {
+	case reason != "":
+		outcome = outcomeRejected
+		m.rejections.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+	case err != nil:
+		outcome = outcomeError
+	default:
+		m.size.Record(ctx, size, metric.WithAttributes(attribute.String("content_type", contentType)))
+	}
+
+	m.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(
+		attribute.String("content_type", contentType),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func (m *uploadMetrics) recordRetry(ctx context.Context) {
+	m.retries.Add(ctx, 1)
+}
+
+// rejectionReason classifies validation errors; other errors return "".
+func rejectionReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrFileTooLarge):
+		return "file_too_large"
+	case errors.Is(err, ErrInvalidFileType):
+		return "invalid_file_type"
+	case errors.Is(err, pii.ErrSensitiveData):
+		return "sensitive_data"
+	case errors.Is(err, ErrInvalidFile):
+		return "invalid_file"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,60 @@
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/pii"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRejectionReason(t *testing.T) {
+	require.Equal(t, "file_too_large", rejectionReason(fmt.Errorf("%w: 11 bytes", ErrFileTooLarge)))
+	require.Equal(t, "invalid_file_type", rejectionReason(fmt.Errorf("%w: zip", ErrInvalidFileType)))
+	require.Equal(t, "sensitive_data", rejectionReason(pii.ErrSensitiveData))
+	require.Equal(t, "invalid_file", rejectionReason(ErrInvalidFile))
+	require.Empty(t, rejectionReason(errors.New("gcs unavailable")))
+	require.Empty(t, rejectionReason(nil))
+}
+
+func TestUploadMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx := context.Background()
+	metrics().recordUpload(ctx, "text/csv", 2048, time.Second, nil)
+	metrics().recordUpload(ctx, "unknown", 0, time.Millisecond, ErrFileTooLarge)
+	metrics().recordRetry(ctx)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	got := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = m.Data
+		}
+	}
+
+	size := got["upload.size"].(metricdata.Histogram[int64])
+	require.Len(t, size.DataPoints, 1)
+	require.Equal(t, int64(2048), size.DataPoints[0].Sum)
+
+	duration := got["upload.duration"].(metricdata.Histogram[float64])
+	require.Len(t, duration.DataPoints, 2)
+
+	rejections := got["upload.rejections"].(metricdata.Sum[int64])
+	require.Len(t, rejections.DataPoints, 1)
+	reason, _ := rejections.DataPoints[0].Attributes.Value(attribute.Key("reason"))
+	require.Equal(t, "file_too_large", reason.AsString())
+
+	retries := got["upload.retries"].(metricdata.Sum[int64])
+	require.Equal(t, int64(1), retries.DataPoints[0].Value)
+}
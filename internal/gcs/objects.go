@@ -0,0 +1,131 @@
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Name        string
+	Size        int64
+	ContentType string
+	Updated     time.Time
+}
+
+// ListObjects returns one page of objects under prefix and the token for the
+// next page, which is empty on the last page.
+func (g *GcsClient) ListObjects(ctx context.Context, prefix string, pageSize int, pageToken string) ([]ObjectInfo, string, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	it := g.GcsClient.Bucket(g.GcsConfig.GcsBucketName).Objects(ctx, &storage.Query{Prefix: prefix})
+	var attrs []*storage.ObjectAttrs
+	next, err := iterator.NewPager(it, pageSize, pageToken).NextPage(&attrs)
+	if err != nil {
+		return nil, "", fmt.Errorf("listing objects under %q: %w", prefix, err)
+	}
+
+	objects := make([]ObjectInfo, 0, len(attrs))
+	for _, a := range attrs {
+		objects = append(objects, objectInfo(a))
+	}
+	return objects, next, nil
+}
+
+// Download opens an object for reading, decrypting CSEK and envelope
+// encrypted objects with the configured keys.
+func (g *GcsClient) Download(ctx context.Context, name string) (io.ReadCloser, ObjectInfo, error) {
+	if err := validateObjectName(name); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	obj := g.GcsClient.Bucket(g.GcsConfig.GcsBucketName).Object(name)
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		return nil, ObjectInfo{}, objectError(name, err)
+	}
+	info := objectInfo(attrs)
+
+	mode := EncryptionMode(attrs.Metadata[MetadataEncryptionMode])
+	if mode == EncryptionCSEK {
+		key, err := g.GcsConfig.Encryption.keyByID(mode, attrs.Metadata[MetadataEncryptionKeyID])
+		if err != nil {
+			return nil, ObjectInfo{}, fmt.Errorf("decrypting %s: %w", name, err)
+		}
+		obj = obj.Key(key)
+	}
+
+	reader, err := obj.NewReader(ctx)
+	if err != nil {
+		return nil, ObjectInfo{}, objectError(name, err)
+	}
+	if mode != EncryptionEnvelope {
+		return reader, info, nil
+	}
+
+	// Envelope objects are bounded by the upload limit, so they are opened in memory.
+	defer reader.Close()
+	sealed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, ObjectInfo{}, fmt.Errorf("reading %s: %w", name, err)
+	}
+	payload, err := g.GcsConfig.Encryption.OpenEnvelope(attrs.Metadata, sealed)
+	if err != nil {
+		return nil, ObjectInfo{}, fmt.Errorf("decrypting %s: %w", name, err)
+	}
+	info.Size = int64(len(payload))
+	return io.NopCloser(bytes.NewReader(payload)), info, nil
+}
+
+// Delete removes an object.
+func (g *GcsClient) Delete(ctx context.Context, name string) error {
+	if err := validateObjectName(name); err != nil {
+		return err
+	}
+
+	if err := g.GcsClient.Bucket(g.GcsConfig.GcsBucketName).Object(name).Delete(ctx); err != nil {
+		return objectError(name, err)
+	}
+	return nil
+}
+
+// validateObjectName only accepts names the upload path could have produced.
+func validateObjectName(name string) error {
+	if name == "" || sanitizeObjectName(name) != name {
+		return fmt.Errorf("%w: invalid object name %q", ErrInvalidFile, name)
+	}
+	return nil
+}
+
+func objectError(name string, err error) error {
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return fmt.Errorf("gcs request failed for %s: %w", name, err)
+}
+
+func objectInfo(attrs *storage.ObjectAttrs) ObjectInfo {
+	return ObjectInfo{
+		Name:        attrs.Name,
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		Updated:     attrs.Updated,
+	}
+}
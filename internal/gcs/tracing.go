@@ -0,0 +1,58 @@
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/api/googleapi"
+)
+
+// Span attributes shared by the upload pipeline spans.
+const (
+	attrFilename    = attribute.Key("file.name")
+	attrFileSize    = attribute.Key("file.size")
+	attrContentType = attribute.Key("file.content_type")
+	attrBucket      = attribute.Key("gcs.bucket")
+	attrAttempt     = attribute.Key("gcs.upload.attempt")
+	attrErrorClass  = attribute.Key("error.class")
+)
+
+// startSpan starts a child span from the global tracer provider, which
+// forwards to the provider set up by observability.New.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, on the span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attrErrorClass.String(errorClass(err)))
+	}
+	span.End()
+}
+
+// errorClass groups errors into a small set of values: the validation
+// reasons of rejectionReason, canceled, gcs_<status> for API errors and
+// internal for everything else.
+func errorClass(err error) string {
+	if reason := rejectionReason(err); reason != "" {
+		return reason
+	}
+
+	var apiErr *googleapi.Error
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("gcs_%d", apiErr.Code)
+	default:
+		return "internal"
+	}
+}
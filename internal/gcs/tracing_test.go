@@ -0,0 +1,56 @@
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/api/googleapi"
+)
+
+func TestUploadWithRetryRecordsAttemptSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(sdktrace.NewTracerProvider()) })
+
+	ctx, parent := startSpan(context.Background(), "test")
+	calls := 0
+	_, err := uploadWithRetry(ctx, []byte("payload"), func(ctx context.Context, r io.Reader) error {
+		calls++
+		if calls == 1 {
+			return &googleapi.Error{Code: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	first, second := spans[0], spans[1]
+	require.Equal(t, "gcs.UploadAttempt", first.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), first.Parent().SpanID())
+	require.Equal(t, codes.Error, first.Status().Code)
+	require.Contains(t, first.Attributes(), attrAttempt.Int(1))
+	require.Contains(t, first.Attributes(), attrErrorClass.String("gcs_503"))
+
+	require.Equal(t, codes.Unset, second.Status().Code)
+	require.Contains(t, second.Attributes(), attrAttempt.Int(2))
+	require.Contains(t, second.Attributes(), attrFileSize.Int(len("payload")))
+}
+
+func TestErrorClass(t *testing.T) {
+	require.Equal(t, "file_too_large", errorClass(fmt.Errorf("%w: 11 bytes", ErrFileTooLarge)))
+	require.Equal(t, "canceled", errorClass(fmt.Errorf("write: %w", context.Canceled)))
+	require.Equal(t, "gcs_403", errorClass(fmt.Errorf("write: %w", &googleapi.Error{Code: http.StatusForbidden})))
+	require.Equal(t, "internal", errorClass(errors.New("boom")))
+}
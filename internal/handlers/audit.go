@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/audit"
+)
+
+// WithAudit records an audit event for every API request. operation names
+// the request, e.g. from the ogen router; requests it returns "" for are not
+// audited. It must run inside WithClientIP. The security handler and the
+// operations fill in the principal, object and size while the request is
+// handled.
+func WithAudit(next http.Handler, recorder *audit.Recorder, operation func(*http.Request) string) http.Handler {
+	if recorder == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := operation(r)
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		event := &audit.Event{
+			Time:      time.Now().UTC(),
+			Operation: name,
+			UserAgent: r.UserAgent(),
+		}
+		event.ClientIP, _ = ClientIPFromContext(r.Context())
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(audit.NewContext(r.Context(), event)))
+
+		event.Status = sw.statusCode()
+		event.Outcome = audit.Outcome(event.Status)
+		recorder.Record(r.Context(), *event)
+	})
+}
+
+// statusWriter remembers the response status code.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
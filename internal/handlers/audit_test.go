@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/audit"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+func TestWithAuditRecordsDeniedRequest(t *testing.T) {
+	buf := &bytes.Buffer{}
+	recorder := audit.NewRecorder(audit.NewWriterSink(buf), newDiscardLogger())
+	sec := NewSecurityHandler(newDiscardLogger(), "admin", "password")
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := sec.HandleBasicAuth(r.Context(), fileupload.DeleteFileOperation, fileupload.BasicAuth{
+			Username: "admin",
+			Password: "wrong",
+		})
+		require.Error(t, err)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	handler := WithClientIP(WithAudit(next, recorder, func(*http.Request) string { return "deleteFile" }), nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/file?name=a.csv", nil)
+	req.Header.Set("User-Agent", "client/1.0")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var event audit.Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+	require.Equal(t, "deleteFile", event.Operation)
+	require.Equal(t, "admin", event.Principal)
+	require.Equal(t, AuthMethodBasic, event.AuthMethod)
+	require.Equal(t, authFailureInvalid, event.Reason)
+	require.Equal(t, "192.0.2.1", event.ClientIP)
+	require.Equal(t, "client/1.0", event.UserAgent)
+	require.Equal(t, http.StatusUnauthorized, event.Status)
+	require.Equal(t, audit.OutcomeDenied, event.Outcome)
+}
+
+func TestWithAuditRecordsObject(t *testing.T) {
+	buf := &bytes.Buffer{}
+	recorder := audit.NewRecorder(audit.NewWriterSink(buf), newDiscardLogger())
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Error(t, requireGrant(r.Context(), "read", "partner-b/data.csv"))
+		_, _ = w.Write([]byte("ok"))
+	})
+	handler := WithAudit(next, recorder, func(*http.Request) string { return "downloadFile" })
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/file", nil))
+
+	var event audit.Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+	require.Equal(t, "partner-b/data.csv", event.Object)
+	require.Equal(t, http.StatusOK, event.Status)
+	require.Equal(t, audit.OutcomeSuccess, event.Outcome)
+}
+
+func TestWithAuditSkipsUnknownOperations(t *testing.T) {
+	buf := &bytes.Buffer{}
+	recorder := audit.NewRecorder(audit.NewWriterSink(buf), newDiscardLogger())
+
+	handler := WithAudit(http.NotFoundHandler(), recorder, func(*http.Request) string { return "" })
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	require.Empty(t, buf.String())
+}
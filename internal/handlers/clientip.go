@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const clientIPContextKey contextKey = "client_ip"
+
+// TrustedProxies are the addresses of proxies whose X-Forwarded-For entries
+// are believed.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies accepts CIDRs and single IPs.
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+func (t TrustedProxies) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithClientIP stores the caller's IP in the request context for the
+// security handler. When the connection comes from a trusted proxy,
+// X-Forwarded-For is read from the right, skipping further trusted proxies,
+// and the first other address is the client. Entries left of it may be
+// forged by the client and are ignored.
+func WithClientIP(next http.Handler, proxies TrustedProxies) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, proxies)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPContextKey, ip)))
+	})
+}
+
+func clientIP(r *http.Request, proxies TrustedProxies) string {
+	ip := remoteIP(r.RemoteAddr)
+	hop, err := netip.ParseAddr(ip)
+	if err != nil || !proxies.contains(hop) {
+		return ip
+	}
+
+	var entries []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		entries = append(entries, strings.Split(header, ",")...)
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(entries[i]))
+		if err != nil {
+			// Nothing left of a malformed entry can be trusted.
+			break
+		}
+		hop = addr.Unmap()
+		if !proxies.contains(hop) {
+			break
+		}
+	}
+	return hop.String()
+}
+
+// ClientIPFromContext returns the IP stored by WithClientIP.
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPContextKey).(string)
+	return ip, ok && ip != ""
+}
+
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{name: "direct", remote: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "untrusted peer cannot forward", remote: "203.0.113.7:5000", forwarded: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "one proxy", remote: "10.0.0.1:5000", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed entries are ignored", remote: "10.0.0.1:5000", forwarded: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "trusted hops are skipped", remote: "10.0.0.1:5000", forwarded: []string{"1.2.3.4, 198.51.100.1, 192.0.2.1", "10.1.1.1"}, want: "198.51.100.1"},
+		{name: "only proxies", remote: "10.0.0.1:5000", forwarded: []string{"10.2.2.2"}, want: "10.2.2.2"},
+		{name: "malformed entry stops the walk", remote: "10.0.0.1:5000", forwarded: []string{"198.51.100.1, garbage"}, want: "10.0.0.1"},
+		{name: "no header", remote: "10.0.0.1:5000", want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, v := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			require.Equal(t, tt.want, clientIP(req, proxies))
+		})
+	}
+}
+
+func TestParseTrustedProxiesRejectsInvalid(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+	_, err = ParseTrustedProxies([]string{"proxy.internal"})
+	require.Error(t, err)
+}
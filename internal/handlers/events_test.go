@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/events"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+type recordingPublisher struct {
+	events []events.Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+func TestNotifyUploadedPublishesEvent(t *testing.T) {
+	publisher := &recordingPublisher{}
+	handler := NewUploadHandler(newDiscardLogger(), gcsClientZero())
+	handler.Events = publisher
+	handler.EventSource = "/file-upload"
+
+	handler.notifyUploaded(context.Background(), "partner-a", fileupload.UploadResponse{Filename: "partner-a/report.csv"})
+
+	require.Len(t, publisher.events, 1)
+	require.Equal(t, events.TypeUploadCompleted, publisher.events[0].Type)
+	require.Equal(t, "/file-upload", publisher.events[0].Source)
+	require.Equal(t, "partner-a/report.csv", publisher.events[0].Subject)
+}
+
+func TestNotifyUploadedIgnoresPublishErrors(t *testing.T) {
+	handler := NewUploadHandler(newDiscardLogger(), gcsClientZero())
+	handler.Events = &recordingPublisher{err: errors.New("outbox full")}
+
+	require.NotPanics(t, func() {
+		handler.notifyUploaded(context.Background(), "partner-a", fileupload.UploadResponse{Filename: "report.csv"})
+	})
+}
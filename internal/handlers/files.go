@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/audit"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+// ListFiles lists objects under the requested prefix
+func (h *UploadHandler) ListFiles(ctx context.Context, params fileupload.ListFilesParams) (*fileupload.FileList, error) {
+	prefix := params.Prefix.Or("")
+	if err := requireGrant(ctx, credentials.PermissionList, prefix); err != nil {
+		return nil, err
+	}
+
+	objects, next, err := h.GcsClient.ListObjects(ctx, prefix, params.PageSize.Or(0), params.PageToken.Or(""))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]fileupload.FileInfo, 0, len(objects))
+	for _, o := range objects {
+		file := fileupload.FileInfo{Name: o.Name, Size: o.Size, Updated: o.Updated}
+		if o.ContentType != "" {
+			file.ContentType = fileupload.NewOptString(o.ContentType)
+		}
+		files = append(files, file)
+	}
+
+	list := &fileupload.FileList{Files: files}
+	if next != "" {
+		list.NextPageToken = fileupload.NewOptString(next)
+	}
+	return list, nil
+}
+
+// DownloadFile streams a stored object
+func (h *UploadHandler) DownloadFile(ctx context.Context, params fileupload.DownloadFileParams) (*fileupload.DownloadFileOKHeaders, error) {
+	startTime := time.Now()
+
+	if err := requireGrant(ctx, credentials.PermissionRead, params.Name); err != nil {
+		return nil, err
+	}
+
+	reader, info, err := h.GcsClient.Download(ctx, params.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Update(ctx, func(e *audit.Event) { e.Size = info.Size })
+
+	// The object is streamed after this returns, so the outcome is logged
+	// once the body is done.
+	body := &downloadBody{reader: reader, done: func(sent int64, err error) {
+		if err != nil {
+			h.logger.WarnContext(ctx, "file download aborted",
+				"filename", info.Name,
+				"size", info.Size,
+				"sent", sent,
+				"duration_ms", time.Since(startTime).Milliseconds(),
+				"error", err,
+			)
+			return
+		}
+		h.logger.InfoContext(ctx, "file downloaded",
+			"filename", info.Name,
+			"size", sent,
+			"duration_ms", time.Since(startTime).Milliseconds(),
+		)
+	}}
+	// ogen does not close response readers, and stops reading when the
+	// client goes away. The request context ends in both cases.
+	context.AfterFunc(ctx, func() { body.finish(context.Cause(ctx)) })
+
+	return &fileupload.DownloadFileOKHeaders{
+		ContentDisposition: fileupload.NewOptString(mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(info.Name)})),
+		Response:           fileupload.DownloadFileOK{Data: body},
+	}, nil
+}
+
+// DeleteFile removes a stored object
+func (h *UploadHandler) DeleteFile(ctx context.Context, params fileupload.DeleteFileParams) error {
+	if err := requireGrant(ctx, credentials.PermissionDelete, params.Name); err != nil {
+		return err
+	}
+
+	if err := h.GcsClient.Delete(ctx, params.Name); err != nil {
+		return err
+	}
+
+	principal, _ := PrincipalFromContext(ctx)
+	h.logger.InfoContext(ctx, "file deleted", "filename", params.Name, "principal", principal.Name)
+	return nil
+}
+
+// requireGrant checks the principal's grants against an object name or prefix.
+func requireGrant(ctx context.Context, permission credentials.Permission, key string) error {
+	audit.Update(ctx, func(e *audit.Event) { e.Object = key })
+	principal, _ := PrincipalFromContext(ctx)
+	if !principal.Grants.Allows(permission, key) {
+		recordAuthFailure(ctx, principal.Method, authFailureForbidden)
+		return fmt.Errorf("%w: %s not allowed on %q", ErrForbidden, permission, key)
+	}
+	return nil
+}
+
+// downloadBody closes the object reader when it is read to the end, fails,
+// or the request ends, whichever is first, and reports how much was read.
+type downloadBody struct {
+	reader io.ReadCloser
+	done   func(sent int64, err error)
+	sent   atomic.Int64
+	once   sync.Once
+}
+
+func (b *downloadBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	b.sent.Add(int64(n))
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			b.finish(nil)
+		} else {
+			b.finish(err)
+		}
+	}
+	return n, err
+}
+
+// finish closes the reader once; err is nil when the whole object was read.
+func (b *downloadBody) finish(err error) {
+	b.once.Do(func() {
+		closeErr := b.reader.Close()
+		if err == nil {
+			err = closeErr
+		}
+		b.done(b.sent.Load(), err)
+	})
+}
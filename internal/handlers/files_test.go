@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/gcs"
+	"gitlab.com/totalprocessing/file-upload/internal/gcs/gcstest"
+)
+
+// newFilesHandler returns a handler over a fake bucket holding objects.
+func newFilesHandler(t *testing.T, objects ...string) (*UploadHandler, *gcstest.Server) {
+	t.Helper()
+	bucket, srv := newFakeGcsClient(t)
+	for _, name := range objects {
+		srv.Put(testBucket, gcstest.Object{Name: name, ContentType: "text/csv", Data: []byte("id\n1\n")})
+	}
+	return NewUploadHandler(newDiscardLogger(), bucket), srv
+}
+
+func requireStatus(t *testing.T, handler *UploadHandler, err error, status int) {
+	t.Helper()
+	require.Error(t, err)
+	require.Equal(t, status, handler.NewError(context.Background(), err).StatusCode)
+}
+
+func TestListFilesPaginates(t *testing.T) {
+	handler, _ := newFilesHandler(t, "partner-a/1.csv", "partner-a/2.csv", "partner-a/3.csv", "partner-b/1.csv")
+	ctx := withPrincipal(context.Background(), "partner-a", credentials.Grant{Permission: credentials.PermissionList, Prefix: "partner-a/"})
+
+	var names []string
+	params := fileupload.ListFilesParams{Prefix: fileupload.NewOptString("partner-a/"), PageSize: fileupload.NewOptInt(2)}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+		list, err := handler.ListFiles(ctx, params)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(list.Files), 2)
+		for _, f := range list.Files {
+			names = append(names, f.Name)
+			require.Equal(t, "text/csv", f.ContentType.Or(""))
+		}
+		token, ok := list.NextPageToken.Get()
+		if !ok {
+			break
+		}
+		params.PageToken = fileupload.NewOptString(token)
+	}
+	require.Equal(t, []string{"partner-a/1.csv", "partner-a/2.csv", "partner-a/3.csv"}, names)
+}
+
+func TestListFilesRequiresGrantForPrefix(t *testing.T) {
+	handler, _ := newFilesHandler(t, "partner-b/1.csv")
+	ctx := withPrincipal(context.Background(), "partner-a", credentials.Grant{Permission: credentials.PermissionList, Prefix: "partner-a/"})
+
+	_, err := handler.ListFiles(ctx, fileupload.ListFilesParams{Prefix: fileupload.NewOptString("partner-b/")})
+	requireStatus(t, handler, err, http.StatusForbidden)
+
+	// Listing the whole bucket needs an unscoped grant.
+	_, err = handler.ListFiles(ctx, fileupload.ListFilesParams{})
+	requireStatus(t, handler, err, http.StatusForbidden)
+}
+
+func TestDownloadFileStreamsObject(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler, _ := newFilesHandler(t, "partner-a/report.csv")
+	handler.logger = slog.New(slog.NewTextHandler(buf, nil))
+	ctx := withPrincipal(context.Background(), "partner-a", credentials.Grant{Permission: credentials.PermissionRead, Prefix: "partner-a/"})
+
+	res, err := handler.DownloadFile(ctx, fileupload.DownloadFileParams{Name: "partner-a/report.csv"})
+	require.NoError(t, err)
+	require.Equal(t, `attachment; filename=report.csv`, res.ContentDisposition.Or(""))
+	require.NotContains(t, buf.String(), "file downloaded", "nothing has been sent yet")
+
+	data, err := io.ReadAll(res.Response.Data)
+	require.NoError(t, err)
+	require.Equal(t, "id\n1\n", string(data))
+	require.Contains(t, buf.String(), "file downloaded")
+}
+
+func TestDownloadFileMapsErrors(t *testing.T) {
+	handler, _ := newFilesHandler(t, "partner-b/report.csv")
+	ctx := withPrincipal(context.Background(), "partner-a", credentials.Grant{Permission: credentials.PermissionRead, Prefix: "partner-a/"})
+
+	_, err := handler.DownloadFile(ctx, fileupload.DownloadFileParams{Name: "partner-a/missing.csv"})
+	require.ErrorIs(t, err, gcs.ErrNotFound)
+	requireStatus(t, handler, err, http.StatusNotFound)
+
+	_, err = handler.DownloadFile(ctx, fileupload.DownloadFileParams{Name: "partner-b/report.csv"})
+	requireStatus(t, handler, err, http.StatusForbidden)
+
+	_, err = handler.DownloadFile(ctx, fileupload.DownloadFileParams{Name: "partner-a/../partner-b/report.csv"})
+	requireStatus(t, handler, err, http.StatusBadRequest)
+}
+
+func TestDeleteFile(t *testing.T) {
+	handler, srv := newFilesHandler(t, "partner-a/report.csv", "partner-b/report.csv")
+	ctx := withPrincipal(context.Background(), "partner-a", credentials.Grant{Permission: credentials.PermissionDelete, Prefix: "partner-a/"})
+
+	require.NoError(t, handler.DeleteFile(ctx, fileupload.DeleteFileParams{Name: "partner-a/report.csv"}))
+	_, ok := srv.Get(testBucket, "partner-a/report.csv")
+	require.False(t, ok)
+
+	err := handler.DeleteFile(ctx, fileupload.DeleteFileParams{Name: "partner-a/report.csv"})
+	requireStatus(t, handler, err, http.StatusNotFound)
+
+	err = handler.DeleteFile(ctx, fileupload.DeleteFileParams{Name: "partner-b/report.csv"})
+	requireStatus(t, handler, err, http.StatusForbidden)
+	_, ok = srv.Get(testBucket, "partner-b/report.csv")
+	require.True(t, ok)
+}
+
+type trackingCloser struct {
+	io.Reader
+	closed int
+}
+
+func (c *trackingCloser) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestDownloadBodyClosesWhenRequestEnds(t *testing.T) {
+	reader := &trackingCloser{Reader: bytes.NewReader([]byte("id\n1\n"))}
+	type result struct {
+		sent int64
+		err  error
+	}
+	done := make(chan result, 2)
+	body := &downloadBody{reader: reader, done: func(sent int64, err error) { done <- result{sent, err} }}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	context.AfterFunc(ctx, func() { body.finish(context.Cause(ctx)) })
+
+	// The client goes away after the first chunk.
+	_, err := body.Read(make([]byte, 2))
+	require.NoError(t, err)
+	cancel()
+
+	select {
+	case r := <-done:
+		require.EqualValues(t, 2, r.sent)
+		require.ErrorIs(t, r.err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("reader not closed when the request ended")
+	}
+	require.Equal(t, 1, reader.closed)
+
+	// Reading to the end afterwards does not close it again.
+	_, _ = io.ReadAll(body)
+	require.Equal(t, 1, reader.closed)
+	require.Empty(t, done)
+}
@@ -3,13 +3,23 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"time"
 
 	"github.com/ogen-go/ogen/ogenerrors"
+	"gitlab.com/totalprocessing/file-upload/internal/audit"
+	"gitlab.com/totalprocessing/file-upload/internal/bqload"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/events"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
 	"gitlab.com/totalprocessing/file-upload/internal/gcs"
+	"gitlab.com/totalprocessing/file-upload/internal/lockout"
+	"gitlab.com/totalprocessing/file-upload/internal/pii"
+	"gitlab.com/totalprocessing/file-upload/internal/ratelimit"
+	"gitlab.com/totalprocessing/file-upload/internal/webhook"
 )
 
 // This ensures my handler follows the spec
@@ -19,6 +29,16 @@ var _ fileupload.Handler = (*UploadHandler)(nil)
 type UploadHandler struct {
 	logger    *slog.Logger
 	GcsClient gcs.GcsClient
+	// LogLevel is the level changed through the admin log-level endpoint.
+	LogLevel *slog.LevelVar
+	// Webhooks is notified of completed uploads; nil disables webhooks.
+	Webhooks *webhook.Dispatcher
+	// Events receives a CloudEvent with EventSource as its source for every
+	// completed upload; nil disables events.
+	Events      events.Publisher
+	EventSource string
+	// Loads starts BigQuery load jobs for matching uploads; nil disables them.
+	Loads *bqload.Loader
 }
 
 // This allows us to mock the client for testing
@@ -32,6 +52,7 @@ func NewUploadHandler(logger *slog.Logger, gcsClient gcs.GcsClient) *UploadHandl
 	return &UploadHandler{
 		logger:    logger,
 		GcsClient: gcsClient,
+		LogLevel:  &slog.LevelVar{},
 	}
 }
 
@@ -39,9 +60,46 @@ func NewUploadHandler(logger *slog.Logger, gcsClient gcs.GcsClient) *UploadHandl
 // TODO: bug when multiple files are uploaded
 func (h *UploadHandler) UploadFile(ctx context.Context, req *fileupload.UploadFileReq) (fileupload.UploadFileRes, error) {
 	startTime := time.Now()
-	response, err := h.GcsClient.UploadToGcs(ctx, req.File.Name, req.File)
+
+	objectName, err := gcs.ObjectName(req.Prefix.Or(""), req.File.Name)
+	if err != nil {
+		return &fileupload.UploadFileBadRequest{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Details: []string{},
+		}, nil
+	}
+
+	audit.Update(ctx, func(e *audit.Event) { e.Object = objectName })
+
+	principal, _ := PrincipalFromContext(ctx)
+	if !principal.Grants.Allows(credentials.PermissionUpload, objectName) {
+		h.logger.WarnContext(ctx, "upload outside granted prefixes",
+			"username", principal.Name,
+			"object", objectName,
+		)
+		recordAuthFailure(ctx, principal.Method, authFailureForbidden)
+		return &fileupload.UploadFileForbidden{
+			Code:    http.StatusForbidden,
+			Message: "forbidden",
+			Details: principal.Grants.Prefixes(credentials.PermissionUpload),
+		}, nil
+	}
+
+	response, err := h.GcsClient.UploadToGcs(ctx, objectName, req.File)
 	if err != nil {
+		var rejected *pii.RejectedError
 		switch {
+		case errors.As(err, &rejected):
+			details := make([]string, 0, len(rejected.Columns))
+			for _, c := range rejected.Columns {
+				details = append(details, fmt.Sprintf("column %d (%s): %d cells containing %v", c.Index, c.Name, c.Cells, c.Kinds))
+			}
+			return &fileupload.UploadFileBadRequest{
+				Code:    http.StatusBadRequest,
+				Message: pii.ErrSensitiveData.Error(),
+				Details: details,
+			}, nil
 		case errors.Is(err, gcs.ErrInvalidFileType),
 			errors.Is(err, gcs.ErrFileTooLarge),
 			errors.Is(err, gcs.ErrInvalidFile):
@@ -60,19 +118,60 @@ func (h *UploadHandler) UploadFile(ctx context.Context, req *fileupload.UploadFi
 		}
 	}
 
-	h.logger.Info("file uploaded successfully",
+	if h.Loads != nil {
+		// The file is stored, so a load that fails to start is only logged.
+		jobID, err := h.Loads.Start(ctx, response.Filename)
+		switch {
+		case errors.Is(err, bqload.ErrEncrypted):
+			h.logger.WarnContext(ctx, "bigquery load skipped", "object", response.Filename, "error", err)
+		case err != nil:
+			h.logger.ErrorContext(ctx, "failed to start bigquery load", "object", response.Filename, "error", err)
+		case jobID != "":
+			response.LoadJobId = fileupload.NewOptString(jobID)
+		}
+	}
+
+	audit.Update(ctx, func(e *audit.Event) {
+		e.Object = response.Filename
+		e.Size = response.FileSize
+		e.Checksum = response.SHA256.Or("")
+	})
+	h.logger.InfoContext(ctx, "file uploaded successfully",
 		"filename", response.Filename,
 		"size", response.FileSize,
 		"gcsPath", response.Gcspath,
+		"load_job", response.LoadJobId.Or(""),
 		"duration_ms", time.Since(startTime).Milliseconds(),
 	)
 
+	h.notifyUploaded(ctx, principal.Name, *response)
+
+	// CORS headers are set by the server's CORS policy.
 	return &fileupload.UploadResponseHeaders{
-		AccessControlAllowOrigin: fileupload.NewOptString("*"),
-		Response:                 *response,
+		Response: *response,
 	}, nil
 }
 
+// notifyUploaded queues the webhooks and events for a completed upload. The
+// upload has succeeded, so a failure only loses the notification.
+func (h *UploadHandler) notifyUploaded(ctx context.Context, uploader string, response fileupload.UploadResponse) {
+	if h.Webhooks != nil {
+		if err := h.Webhooks.Publish(ctx, uploader, response); err != nil {
+			h.logger.ErrorContext(ctx, "failed to queue upload webhook", "object", response.Filename, "error", err)
+		}
+	}
+
+	if h.Events != nil {
+		event, err := events.NewUploadCompleted(h.EventSource, uploader, response)
+		if err == nil {
+			err = h.Events.Publish(ctx, event)
+		}
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to publish upload event", "object", response.Filename, "error", err)
+		}
+	}
+}
+
 func (h *UploadHandler) NewError(ctx context.Context, err error) *fileupload.ErrorStatusCodeWithHeaders {
 	statusCode := http.StatusInternalServerError
 	message := "internal server error"
@@ -83,6 +182,39 @@ func (h *UploadHandler) NewError(ctx context.Context, err error) *fileupload.Err
 		message = "unauthorized"
 	}
 
+	if errors.Is(err, ErrForbidden) {
+		statusCode = http.StatusForbidden
+		message = "forbidden"
+	}
+
+	var retryAfter fileupload.OptInt
+	var lockedErr *lockout.LockedError
+	if errors.As(err, &lockedErr) {
+		statusCode = http.StatusTooManyRequests
+		message = "too many requests"
+		retryAfter = fileupload.NewOptInt(int(math.Ceil(lockedErr.RetryAfter.Seconds())))
+	}
+
+	details := []string{}
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		statusCode = http.StatusTooManyRequests
+		message = "too many requests"
+		details = []string{limitErr.Reason}
+		retryAfter = fileupload.NewOptInt(int(math.Ceil(limitErr.RetryAfter.Seconds())))
+	}
+
+	if errors.Is(err, gcs.ErrNotFound) || errors.Is(err, bqload.ErrJobNotFound) {
+		statusCode = http.StatusNotFound
+		message = "not found"
+	}
+
+	if errors.Is(err, gcs.ErrInvalidFile) {
+		statusCode = http.StatusBadRequest
+		message = "bad request"
+		details = []string{err.Error()}
+	}
+
 	var decodeErr *ogenerrors.DecodeRequestError
 	if errors.As(err, &decodeErr) {
 		statusCode = http.StatusBadRequest
@@ -94,12 +226,12 @@ func (h *UploadHandler) NewError(ctx context.Context, err error) *fileupload.Err
 	}
 
 	return &fileupload.ErrorStatusCodeWithHeaders{
-		StatusCode:               statusCode,
-		AccessControlAllowOrigin: fileupload.NewOptString("*"),
+		StatusCode: statusCode,
+		RetryAfter: retryAfter,
 		Response: fileupload.Error{
 			Code:    int32(statusCode),
 			Message: message,
-			Details: []string{},
+			Details: details,
 		},
 	}
 }
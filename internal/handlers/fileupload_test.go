@@ -2,14 +2,23 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
+	ht "github.com/ogen-go/ogen/http"
 	"github.com/ogen-go/ogen/ogenerrors"
 	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
 	"gitlab.com/totalprocessing/file-upload/internal/gcs"
+	"gitlab.com/totalprocessing/file-upload/internal/gcs/gcstest"
+	"gitlab.com/totalprocessing/file-upload/internal/lockout"
+	"gitlab.com/totalprocessing/file-upload/internal/pii"
 )
 
 func TestNewErrorDefaultsToInternalServerError(t *testing.T) {
@@ -19,7 +28,7 @@ func TestNewErrorDefaultsToInternalServerError(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
 	require.Equal(t, int32(http.StatusInternalServerError), res.Response.Code)
 	require.Equal(t, "internal server error", res.Response.Message)
-	require.Equal(t, "*", res.AccessControlAllowOrigin.Value)
+	require.False(t, res.AccessControlAllowOrigin.IsSet())
 }
 
 func TestNewErrorMapsSecurityErrorToUnauthorized(t *testing.T) {
@@ -39,6 +48,23 @@ func TestNewErrorMapsSecurityErrorToUnauthorized(t *testing.T) {
 	require.Equal(t, "unauthorized", res.Response.Message)
 }
 
+func TestNewErrorMapsForbiddenToForbidden(t *testing.T) {
+	handler := NewUploadHandler(newDiscardLogger(), gcsClientZero())
+
+	res := handler.NewError(context.Background(), &ogenerrors.SecurityError{
+		OperationContext: ogenerrors.OperationContext{
+			Name: fileupload.UploadFileOperation,
+			ID:   "uploadFile",
+		},
+		Security: "BasicAuth",
+		Err:      ErrForbidden,
+	})
+
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+	require.Equal(t, int32(http.StatusForbidden), res.Response.Code)
+	require.Equal(t, "forbidden", res.Response.Message)
+}
+
 func TestNewErrorMapsDecodeErrorToBadRequest(t *testing.T) {
 	handler := NewUploadHandler(newDiscardLogger(), gcsClientZero())
 
@@ -55,6 +81,97 @@ func TestNewErrorMapsDecodeErrorToBadRequest(t *testing.T) {
 	require.Equal(t, "bad request", res.Response.Message)
 }
 
+func TestNewErrorMapsLockoutToTooManyRequests(t *testing.T) {
+	handler := NewUploadHandler(newDiscardLogger(), gcsClientZero())
+
+	res := handler.NewError(context.Background(), &ogenerrors.SecurityError{
+		OperationContext: ogenerrors.OperationContext{
+			Name: fileupload.UploadFileOperation,
+			ID:   "uploadFile",
+		},
+		Security: "BasicAuth",
+		Err:      &lockout.LockedError{Key: "user:admin", RetryAfter: 1500 * time.Millisecond},
+	})
+
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, "too many requests", res.Response.Message)
+	require.Equal(t, 2, res.RetryAfter.Value)
+}
+
 func gcsClientZero() gcs.GcsClient {
 	return gcs.GcsClient{}
 }
+
+const testBucket = "test-bucket"
+
+// newFakeGcsClient returns a client backed by an in-memory GCS.
+func newFakeGcsClient(t *testing.T) (gcs.GcsClient, *gcstest.Server) {
+	t.Helper()
+	srv := gcstest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := srv.Client(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return gcs.GcsClient{
+		Logger:    newDiscardLogger(),
+		GcsClient: client,
+		GcsConfig: gcs.GcsConfig{GcsBucketName: testBucket},
+	}, srv
+}
+
+// uploadRequest builds the request ogen decodes for a multipart upload.
+func uploadRequest(name, content string) *fileupload.UploadFileReq {
+	return &fileupload.UploadFileReq{File: ht.MultipartFile{
+		Name: name,
+		File: strings.NewReader(content),
+		Size: int64(len(content)),
+	}}
+}
+
+// withPrincipal authenticates ctx as name with grants.
+func withPrincipal(ctx context.Context, name string, grants ...credentials.Grant) context.Context {
+	return context.WithValue(ctx, userContextKey, Principal{Name: name, Method: AuthMethodBasic, Grants: grants})
+}
+
+func TestUploadFileStoresObject(t *testing.T) {
+	bucket, srv := newFakeGcsClient(t)
+	handler := NewUploadHandler(newDiscardLogger(), bucket)
+	ctx := withPrincipal(context.Background(), "partner-a", credentials.Grant{Permission: credentials.PermissionUpload, Prefix: "partner-a/"})
+
+	req := uploadRequest("report.csv", "id,amount\n1,2\n")
+	req.Prefix = fileupload.NewOptString("partner-a")
+	res, err := handler.UploadFile(ctx, req)
+	require.NoError(t, err)
+
+	uploaded, ok := res.(*fileupload.UploadResponseHeaders)
+	require.True(t, ok, "unexpected response %T", res)
+	require.Equal(t, "partner-a/report.csv", uploaded.Response.Filename)
+
+	obj, ok := srv.Get(testBucket, "partner-a/report.csv")
+	require.True(t, ok)
+	require.Equal(t, "text/csv", obj.ContentType)
+}
+
+func TestUploadFileChecksumMatchesRedactedObject(t *testing.T) {
+	bucket, srv := newFakeGcsClient(t)
+	scanner, err := pii.New(pii.Config{Action: pii.ActionMask})
+	require.NoError(t, err)
+	bucket.GcsConfig.PII = scanner
+	handler := NewUploadHandler(newDiscardLogger(), bucket)
+	ctx := withPrincipal(context.Background(), "admin", credentials.Grant{Permission: credentials.PermissionAdmin})
+
+	res, err := handler.UploadFile(ctx, uploadRequest("cards.csv", "id,card\n1,4111 1111 1111 1111\n"))
+	require.NoError(t, err)
+	uploaded, ok := res.(*fileupload.UploadResponseHeaders)
+	require.True(t, ok, "unexpected response %T", res)
+	require.NotEmpty(t, uploaded.Response.SensitiveColumns)
+
+	obj, ok := srv.Get(testBucket, "cards.csv")
+	require.True(t, ok)
+	require.NotContains(t, string(obj.Data), "4111 1111")
+	checksum := sha256.Sum256(obj.Data)
+	require.Equal(t, hex.EncodeToString(checksum[:]), uploaded.Response.SHA256.Or(""))
+	require.EqualValues(t, len(obj.Data), uploaded.Response.FileSize)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.com/totalprocessing/file-upload/internal/bqload"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+// GetLoadStatus reports on the BigQuery load job started for an upload.
+// The caller needs read access to the object the job loads; jobs for other
+// objects are reported as not found, so ids don't reveal which jobs exist.
+func (h *UploadHandler) GetLoadStatus(ctx context.Context, params fileupload.GetLoadStatusParams) (*fileupload.LoadStatus, error) {
+	if h.Loads == nil {
+		return nil, bqload.ErrJobNotFound
+	}
+
+	status, err := h.Loads.Status(ctx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireGrant(ctx, credentials.PermissionRead, status.Object); err != nil {
+		return nil, fmt.Errorf("%w: %s", bqload.ErrJobNotFound, params.ID)
+	}
+
+	res := &fileupload.LoadStatus{
+		JobId:  status.JobID,
+		State:  fileupload.LoadStatusState(status.State),
+		Object: status.Object,
+		Table:  status.Table,
+		Errors: status.Errors,
+	}
+	if status.State == string(fileupload.LoadStatusStateDONE) {
+		res.OutputRows = fileupload.NewOptInt64(status.OutputRows)
+	}
+	return res, nil
+}
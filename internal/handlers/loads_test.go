@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/bqload"
+	"gitlab.com/totalprocessing/file-upload/internal/bqload/bqtest"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+func TestGetLoadStatusWithoutLoadsIsNotFound(t *testing.T) {
+	handler := NewUploadHandler(newDiscardLogger(), gcsClientZero())
+
+	_, err := handler.GetLoadStatus(context.Background(), fileupload.GetLoadStatusParams{ID: "upload_20261018T120000_abc"})
+	require.ErrorIs(t, err, bqload.ErrJobNotFound)
+
+	res := handler.NewError(context.Background(), err)
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+// newLoadingHandler loads every CSV under partner-a/ into BigQuery.
+func newLoadingHandler(t *testing.T) *UploadHandler {
+	t.Helper()
+	bucket, _ := newFakeGcsClient(t)
+	srv := bqtest.NewServer()
+	t.Cleanup(srv.Close)
+	client, err := srv.Client(context.Background(), "project")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	handler := NewUploadHandler(newDiscardLogger(), bucket)
+	handler.Loads = bqload.New(client, testBucket, []bqload.Rule{{Prefix: "partner-a/", Dataset: "raw", Table: "partner_a"}}, nil)
+	return handler
+}
+
+func uploadForLoad(t *testing.T, handler *UploadHandler) string {
+	t.Helper()
+	ctx := withPrincipal(context.Background(), "partner-a", credentials.Grant{Permission: credentials.PermissionUpload, Prefix: "partner-a/"})
+	req := uploadRequest("report.csv", "id,amount\n1,2\n")
+	req.Prefix = fileupload.NewOptString("partner-a")
+
+	res, err := handler.UploadFile(ctx, req)
+	require.NoError(t, err)
+	uploaded, ok := res.(*fileupload.UploadResponseHeaders)
+	require.True(t, ok, "unexpected response %T", res)
+	require.True(t, uploaded.Response.LoadJobId.IsSet())
+	return uploaded.Response.LoadJobId.Value
+}
+
+func TestUploadFileReportsLoadJob(t *testing.T) {
+	handler := newLoadingHandler(t)
+	jobID := uploadForLoad(t, handler)
+
+	ctx := withPrincipal(context.Background(), "partner-a", credentials.Grant{Permission: credentials.PermissionRead, Prefix: "partner-a/"})
+	status, err := handler.GetLoadStatus(ctx, fileupload.GetLoadStatusParams{ID: jobID})
+	require.NoError(t, err)
+	require.Equal(t, fileupload.LoadStatusStatePENDING, status.State)
+	require.Equal(t, "partner-a/report.csv", status.Object)
+	require.Equal(t, "project.raw.partner_a", status.Table)
+}
+
+func TestGetLoadStatusHidesJobsOutsideGrants(t *testing.T) {
+	handler := newLoadingHandler(t)
+	jobID := uploadForLoad(t, handler)
+
+	ctx := withPrincipal(context.Background(), "partner-b", credentials.Grant{Permission: credentials.PermissionRead, Prefix: "partner-b/"})
+	_, err := handler.GetLoadStatus(ctx, fileupload.GetLoadStatusParams{ID: jobID})
+	require.ErrorIs(t, err, bqload.ErrJobNotFound)
+	require.Equal(t, http.StatusNotFound, handler.NewError(ctx, err).StatusCode)
+
+	_, unknownErr := handler.GetLoadStatus(ctx, fileupload.GetLoadStatusParams{ID: "upload_20261018T120000_000000000000"})
+	require.Equal(t, handler.NewError(ctx, unknownErr).Response, handler.NewError(ctx, err).Response)
+}
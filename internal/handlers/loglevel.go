@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+// GetLogLevel returns the current level of LogLevel.
+// Like every operation missing from operationPermissions it requires admin.
+func (h *UploadHandler) GetLogLevel(ctx context.Context) (*fileupload.LogLevel, error) {
+	return &fileupload.LogLevel{Level: toLogLevel(h.LogLevel.Level())}, nil
+}
+
+// SetLogLevel changes LogLevel until the server restarts.
+func (h *UploadHandler) SetLogLevel(ctx context.Context, req *fileupload.LogLevel) (*fileupload.LogLevel, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+		return nil, err
+	}
+
+	previous := h.LogLevel.Level()
+	h.LogLevel.Set(level)
+
+	principal, _ := PrincipalFromContext(ctx)
+	h.logger.WarnContext(ctx, "log level changed",
+		"from", previous.String(),
+		"to", level.String(),
+		"principal", principal.Name,
+	)
+	return &fileupload.LogLevel{Level: toLogLevel(level)}, nil
+}
+
+// toLogLevel rounds level down to the nearest named level.
+func toLogLevel(level slog.Level) fileupload.LogLevelLevel {
+	switch {
+	case level >= slog.LevelError:
+		return fileupload.LogLevelLevelERROR
+	case level >= slog.LevelWarn:
+		return fileupload.LogLevelLevelWARN
+	case level >= slog.LevelInfo:
+		return fileupload.LogLevelLevelINFO
+	default:
+		return fileupload.LogLevelLevelDEBUG
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	handler := NewUploadHandler(newDiscardLogger(), gcsClientZero())
+	handler.LogLevel.Set(slog.LevelInfo)
+
+	res, err := handler.SetLogLevel(context.Background(), &fileupload.LogLevel{Level: fileupload.LogLevelLevelDEBUG})
+	require.NoError(t, err)
+	require.Equal(t, fileupload.LogLevelLevelDEBUG, res.Level)
+	require.Equal(t, slog.LevelDebug, handler.LogLevel.Level())
+
+	res, err = handler.GetLogLevel(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, fileupload.LogLevelLevelDEBUG, res.Level)
+}
+
+func TestToLogLevelRoundsDown(t *testing.T) {
+	require.Equal(t, fileupload.LogLevelLevelINFO, toLogLevel(slog.LevelInfo+2))
+	require.Equal(t, fileupload.LogLevelLevelDEBUG, toLogLevel(slog.LevelDebug-4))
+	require.Equal(t, fileupload.LogLevelLevelERROR, toLogLevel(slog.LevelError+4))
+}
+
+func TestSetLogLevelRequiresAdmin(t *testing.T) {
+	users, err := credentials.NewStatic(credentials.User{
+		Username: "uploader",
+		Password: "testpass",
+		Roles:    []string{"upload", "read", "list", "delete"},
+	})
+	require.NoError(t, err)
+	handler := NewStoreSecurityHandler(newDiscardLogger(), SecurityConfig{Users: users})
+
+	_, err = handler.HandleBasicAuth(context.Background(), fileupload.SetLogLevelOperation, fileupload.BasicAuth{
+		Username: "uploader",
+		Password: "testpass",
+	})
+	require.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"gitlab.com/totalprocessing/file-upload/internal/audit"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "gitlab.com/totalprocessing/file-upload/internal/handlers"
+
+// Reasons recorded on auth.failures.
+const (
+	authFailureDisabled   = "scheme_disabled"
+	authFailureInvalid    = "invalid_credentials"
+	authFailureLockedOut  = "locked_out"
+	authFailureMissing    = "missing_credentials"
+	authFailureNoIdentity = "no_identity"
+	authFailureForbidden  = "forbidden"
+	authMethodNone        = "none"
+)
+
+var authFailures = sync.OnceValue(func() metric.Int64Counter {
+	counter, err := otel.Meter(meterName).Int64Counter("auth.failures",
+		metric.WithDescription("Failed authentication and authorization attempts, by method and reason"),
+	)
+	otel.Handle(err)
+	return counter
+})
+
+// recordAuthFailure also records the reason on the request's audit event.
+func recordAuthFailure(ctx context.Context, method, reason string) {
+	audit.Update(ctx, func(e *audit.Event) {
+		e.AuthMethod = method
+		e.Reason = reason
+	})
+	authFailures().Add(ctx, 1, metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("reason", reason),
+	))
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestAuthFailuresMetric(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	handler := NewSecurityHandler(newDiscardLogger(), "testuser", "testpass")
+	_, err := handler.HandleBasicAuth(context.Background(), fileupload.UploadFileOperation, fileupload.BasicAuth{
+		Username: "testuser",
+		Password: "wrong",
+	})
+	require.Error(t, err)
+
+	_, err = handler.HandleApiKeyAuth(context.Background(), fileupload.UploadFileOperation, fileupload.ApiKeyAuth{APIKey: "fuk_x"})
+	require.Error(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	counts := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "auth.failures" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				method, _ := dp.Attributes.Value(attribute.Key("method"))
+				reason, _ := dp.Attributes.Value(attribute.Key("reason"))
+				counts[method.AsString()+"/"+reason.AsString()] += dp.Value
+			}
+		}
+	}
+
+	require.Equal(t, map[string]int64{
+		"basic/invalid_credentials": 1,
+		"apikey/scheme_disabled":    1,
+	}, counts)
+}
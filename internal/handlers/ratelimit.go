@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ogen-go/ogen/middleware"
+	"gitlab.com/totalprocessing/file-upload/internal/audit"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/ratelimit"
+)
+
+const responseHeaderContextKey contextKey = "response_header"
+
+// WithResponseHeaders exposes the response headers to ogen middleware, which
+// only sees the request, so it can add headers the spec does not model.
+func WithResponseHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), responseHeaderContextKey, w.Header())))
+	})
+}
+
+func responseHeaderFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(responseHeaderContextKey).(http.Header)
+	return header
+}
+
+// RateLimit is the server middleware that applies the limiter per principal.
+// The principal is set by the security handler, which ogen runs before any
+// middleware. Uploads are counted against the
+// daily quotas and released again if they fail.
+func RateLimit(logger *slog.Logger, limiter *ratelimit.Limiter) fileupload.Middleware {
+	return func(req middleware.Request, next middleware.Next) (middleware.Response, error) {
+		principal, ok := PrincipalFromContext(req.Context)
+		if !ok {
+			return next(req)
+		}
+
+		header := responseHeaderFromContext(req.Context)
+
+		status, err := limiter.Allow(principal.Name)
+		setRateLimitHeaders(header, limiter, status)
+		if err != nil {
+			audit.Update(req.Context, func(e *audit.Event) { e.Reason = "rate_limited" })
+			logger.WarnContext(req.Context, "rate limited", "operation", req.OperationName, "principal", principal.Name, "error", err)
+			return middleware.Response{}, err
+		}
+
+		upload, ok := req.Body.(*fileupload.UploadFileReq)
+		if !ok || !limiter.QuotaLimited() {
+			return next(req)
+		}
+
+		size := upload.File.Size
+		quota, err := limiter.Reserve(principal.Name, size)
+		status.BytesRemaining, status.FilesRemaining = quota.BytesRemaining, quota.FilesRemaining
+		setRateLimitHeaders(header, limiter, status)
+		if err != nil {
+			audit.Update(req.Context, func(e *audit.Event) { e.Reason = "quota_exceeded" })
+			logger.WarnContext(req.Context, "upload quota exceeded", "principal", principal.Name, "size", size, "error", err)
+			return middleware.Response{}, err
+		}
+
+		resp, err := next(req)
+		if _, uploaded := resp.Type.(*fileupload.UploadResponseHeaders); err != nil || !uploaded {
+			limiter.Release(principal.Name, size)
+		}
+		return resp, err
+	}
+}
+
+func setRateLimitHeaders(header http.Header, limiter *ratelimit.Limiter, status ratelimit.Status) {
+	if header == nil {
+		return
+	}
+	if limiter.RateLimited() {
+		header.Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	}
+	if limiter.QuotaLimited() {
+		header.Set("X-RateLimit-Bytes-Remaining", strconv.FormatInt(status.BytesRemaining, 10))
+		header.Set("X-RateLimit-Files-Remaining", strconv.FormatInt(status.FilesRemaining, 10))
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(int64(time.Until(status.QuotaReset).Seconds()), 10))
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ogen-go/ogen/middleware"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/ratelimit"
+)
+
+func rateLimitRequest(size int64) (middleware.Request, http.Header) {
+	header := http.Header{}
+	ctx := context.WithValue(context.Background(), responseHeaderContextKey, header)
+	ctx = context.WithValue(ctx, userContextKey, Principal{Name: "partner-a", Method: AuthMethodBasic, Grants: credentials.Grants{{Permission: credentials.PermissionUpload}}})
+	return middleware.Request{
+		Context:       ctx,
+		OperationName: fileupload.UploadFileOperation,
+		Body:          uploadRequest("report.csv", strings.Repeat("a,b\n", int(size/4))),
+	}, header
+}
+
+// uploadVia returns a next handler that runs the real UploadFile.
+func uploadVia(handler *UploadHandler) middleware.Next {
+	return func(req middleware.Request) (middleware.Response, error) {
+		res, err := handler.UploadFile(req.Context, req.Body.(*fileupload.UploadFileReq))
+		return middleware.Response{Type: res}, err
+	}
+}
+
+func TestRateLimitSetsHeadersAndLimits(t *testing.T) {
+	mw := RateLimit(newDiscardLogger(), ratelimit.New(ratelimit.Config{RequestsPerSecond: 0.001, Burst: 1, DailyBytes: 100}))
+	bucket, _ := newFakeGcsClient(t)
+	uploaded := uploadVia(NewUploadHandler(newDiscardLogger(), bucket))
+
+	req, header := rateLimitRequest(32)
+	_, err := mw(req, uploaded)
+	require.NoError(t, err)
+	require.Equal(t, "1", header.Get("X-RateLimit-Limit"))
+	require.Equal(t, "0", header.Get("X-RateLimit-Remaining"))
+	require.Equal(t, "68", header.Get("X-RateLimit-Bytes-Remaining"))
+	require.NotEmpty(t, header.Get("X-RateLimit-Reset"))
+
+	req, _ = rateLimitRequest(32)
+	_, err = mw(req, uploaded)
+	require.ErrorIs(t, err, ratelimit.ErrLimited)
+}
+
+func TestRateLimitReleasesFailedUploads(t *testing.T) {
+	mw := RateLimit(newDiscardLogger(), ratelimit.New(ratelimit.Config{DailyFiles: 1}))
+
+	req, _ := rateLimitRequest(10)
+	_, err := mw(req, func(req middleware.Request) (middleware.Response, error) {
+		return middleware.Response{}, errors.New("gcs unavailable")
+	})
+	require.Error(t, err)
+
+	bucket, _ := newFakeGcsClient(t)
+	uploaded := uploadVia(NewUploadHandler(newDiscardLogger(), bucket))
+
+	req, header := rateLimitRequest(12)
+	_, err = mw(req, uploaded)
+	require.NoError(t, err)
+	require.Equal(t, "0", header.Get("X-RateLimit-Files-Remaining"))
+
+	// The successful upload used up the quota, so it must not be released.
+	req, _ = rateLimitRequest(12)
+	_, err = mw(req, uploaded)
+	require.ErrorIs(t, err, ratelimit.ErrLimited)
+}
@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"context"
-	"crypto/subtle"
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"log/slog"
-	"strings"
+	"net/http"
 	"time"
 
+	"github.com/ogen-go/ogen/ogenerrors"
+	"gitlab.com/totalprocessing/file-upload/internal/audit"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
-	"golang.org/x/crypto/bcrypt"
+	"gitlab.com/totalprocessing/file-upload/internal/lockout"
 )
 
 var _ fileupload.SecurityHandler = (*SecurityHandler)(nil)
@@ -18,64 +22,338 @@ type contextKey string
 
 const userContextKey contextKey = "user"
 
+// ErrForbidden is returned when a principal is authenticated but not allowed
+// to perform the operation.
+var ErrForbidden = errors.New("forbidden")
+
+// operationPermissions maps each operation to the permission it requires.
+// Operations missing from the map are not tied to an object key and require
+// an unscoped admin grant.
+var operationPermissions = map[fileupload.OperationName]credentials.Permission{
+	fileupload.UploadFileOperation:    credentials.PermissionUpload,
+	fileupload.ListFilesOperation:     credentials.PermissionList,
+	fileupload.DownloadFileOperation:  credentials.PermissionRead,
+	fileupload.DeleteFileOperation:    credentials.PermissionDelete,
+	fileupload.GetLoadStatusOperation: credentials.PermissionRead,
+}
+
+// Authentication methods recorded on the principal.
+const (
+	AuthMethodBasic  = "basic"
+	AuthMethodAPIKey = "apikey"
+	AuthMethodJWT    = "jwt"
+	AuthMethodMTLS   = "mtls"
+)
+
+// Principal is the authenticated caller stored in the request context.
+type Principal struct {
+	Name   string
+	Method string
+	Grants credentials.Grants
+}
+
+// PrincipalFromContext returns the principal stored by the security handler.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(userContextKey).(Principal)
+	return p, ok
+}
+
 type SecurityHandler struct {
-	logger       *slog.Logger
-	AuthUsername string
-	AuthPassword string
+	logger  *slog.Logger
+	Users   *credentials.Store
+	APIKeys *credentials.APIKeyStore
+	JWT     *credentials.JWTVerifier
+	Certs   *credentials.ClientCertMapper
+	Lockout *lockout.Guard
+}
+
+// SecurityConfig holds the credential stores used by the security handler.
+// A nil store disables the corresponding scheme.
+type SecurityConfig struct {
+	Users   *credentials.Store
+	APIKeys *credentials.APIKeyStore
+	JWT     *credentials.JWTVerifier
+	Certs   *credentials.ClientCertMapper
+	Lockout *lockout.Guard
 }
 
 // This allows us to mock the client for testing
 type SecurityClient interface {
 	HandleBasicAuth(ctx context.Context, operationName fileupload.OperationName, auth fileupload.BasicAuth) (context.Context, error)
+	HandleApiKeyAuth(ctx context.Context, operationName fileupload.OperationName, auth fileupload.ApiKeyAuth) (context.Context, error)
+	HandleBearerAuth(ctx context.Context, operationName fileupload.OperationName, auth fileupload.BearerAuth) (context.Context, error)
 }
 
-// NewUploadHandler creates a new security handler
+// NewSecurityHandler creates a security handler for a single username/password pair
 func NewSecurityHandler(logger *slog.Logger, username, password string) *SecurityHandler {
+	return NewStoreSecurityHandler(logger, SecurityConfig{
+		Users: credentials.NewSingleUser(username, password),
+	})
+}
+
+// NewStoreSecurityHandler creates a security handler backed by credential stores
+func NewStoreSecurityHandler(logger *slog.Logger, cfg SecurityConfig) *SecurityHandler {
 	return &SecurityHandler{
-		logger:       logger,
-		AuthUsername: username,
-		AuthPassword: password,
+		logger:  logger,
+		Users:   cfg.Users,
+		APIKeys: cfg.APIKeys,
+		JWT:     cfg.JWT,
+		Certs:   cfg.Certs,
+		Lockout: cfg.Lockout,
 	}
 }
 
 // HandleBasicAuth handles basic authentication
 func (h *SecurityHandler) HandleBasicAuth(ctx context.Context, operationName fileupload.OperationName, auth fileupload.BasicAuth) (context.Context, error) {
 	startTime := time.Now()
+	audit.Update(ctx, func(e *audit.Event) { e.Principal = auth.Username })
+
+	if h.Users == nil {
+		recordAuthFailure(ctx, AuthMethodBasic, authFailureDisabled)
+		return ctx, errors.New("error basic auth disabled")
+	}
+
+	lockoutKeys := []string{lockout.UserKey(auth.Username)}
+	clientIP, ok := ClientIPFromContext(ctx)
+	if ok {
+		lockoutKeys = append(lockoutKeys, lockout.IPKey(clientIP))
+	}
 
-	// Evaluate both checks to avoid username-dependent short-circuit timing.
-	usernameMatches := constantTimeEqual(auth.Username, h.AuthUsername)
-	passwordMatches := h.passwordMatches(auth.Password)
-	if !(usernameMatches && passwordMatches) {
-		h.logger.Warn("authentication unsuccessful",
+	if err := h.checkLockout(ctx, auth.Username, clientIP, lockoutKeys); err != nil {
+		return ctx, err
+	}
+
+	user, ok := h.Users.Authenticate(auth.Username, auth.Password)
+	if !ok {
+		h.logger.WarnContext(ctx, "authentication unsuccessful",
 			"username", auth.Username,
+			"client_ip", clientIP,
 			"duration_ms", time.Since(startTime).Milliseconds(),
 		)
+		recordAuthFailure(ctx, AuthMethodBasic, authFailureInvalid)
+		if err := h.failLockout(ctx, auth.Username, clientIP, lockoutKeys); err != nil {
+			return ctx, err
+		}
 		return ctx, errors.New("error credentials invalid")
 	}
 
-	h.logger.Info("authenticated successfully",
+	// Only the username is cleared, so a valid login cannot reset the
+	// failures counted against a client IP.
+	if h.Lockout != nil {
+		if err := h.Lockout.Succeed(ctx, lockout.UserKey(auth.Username)); err != nil {
+			h.logger.WarnContext(ctx, "clearing lockout failed", "username", auth.Username, "error", err)
+		}
+	}
+
+	principal := Principal{Name: user.Username, Method: AuthMethodBasic, Grants: user.Grants}
+	if err := h.authorize(ctx, operationName, principal); err != nil {
+		return ctx, err
+	}
+
+	h.logger.InfoContext(ctx, "authenticated successfully",
 		"operation", operationName,
-		"username", auth.Username,
+		"username", user.Username,
 		"duration_ms", time.Since(startTime).Milliseconds(),
 	)
 
-	return context.WithValue(ctx, userContextKey, h.AuthUsername), nil
+	return context.WithValue(ctx, userContextKey, principal), nil
 }
 
-func (h *SecurityHandler) passwordMatches(password string) bool {
-	if strings.HasPrefix(h.AuthPassword, "$2a$") ||
-		strings.HasPrefix(h.AuthPassword, "$2b$") ||
-		strings.HasPrefix(h.AuthPassword, "$2y$") {
-		return bcrypt.CompareHashAndPassword([]byte(h.AuthPassword), []byte(password)) == nil
+// HandleApiKeyAuth handles X-API-Key authentication
+func (h *SecurityHandler) HandleApiKeyAuth(ctx context.Context, operationName fileupload.OperationName, auth fileupload.ApiKeyAuth) (context.Context, error) {
+	startTime := time.Now()
+
+	if h.APIKeys == nil {
+		recordAuthFailure(ctx, AuthMethodAPIKey, authFailureDisabled)
+		return ctx, errors.New("error api keys disabled")
+	}
+
+	// Only the non-secret prefix is ever logged.
+	prefix, _ := credentials.APIKeyPrefix(auth.APIKey)
+	previousUse, usedBefore := h.APIKeys.LastUsed(prefix)
+
+	key, err := h.APIKeys.Authenticate(auth.APIKey)
+	if err != nil {
+		h.logger.WarnContext(ctx, "authentication unsuccessful",
+			"key_prefix", prefix,
+			"error", err,
+			"duration_ms", time.Since(startTime).Milliseconds(),
+		)
+		recordAuthFailure(ctx, AuthMethodAPIKey, authFailureInvalid)
+		return ctx, fmt.Errorf("error credentials invalid: %w", err)
+	}
+
+	name := key.Name
+	if name == "" {
+		name = credentials.APIKeyScheme + "_" + key.Prefix
+	}
+
+	principal := Principal{Name: name, Method: AuthMethodAPIKey, Grants: key.Grants}
+	if err := h.authorize(ctx, operationName, principal); err != nil {
+		return ctx, err
+	}
+
+	attrs := []any{
+		"operation", operationName,
+		"key_name", name,
+		"key_prefix", key.Prefix,
+		"duration_ms", time.Since(startTime).Milliseconds(),
+	}
+	// Lets operators spot keys that sat unused for a long time.
+	if usedBefore {
+		attrs = append(attrs, "key_last_used", previousUse.UTC().Format(time.RFC3339))
 	}
+	h.logger.InfoContext(ctx, "authenticated successfully", attrs...)
 
-	return constantTimeEqual(password, h.AuthPassword)
+	return context.WithValue(ctx, userContextKey, principal), nil
 }
 
-func constantTimeEqual(a, b string) bool {
-	if len(a) != len(b) {
-		return false
+// HandleBearerAuth handles OIDC bearer token authentication
+func (h *SecurityHandler) HandleBearerAuth(ctx context.Context, operationName fileupload.OperationName, auth fileupload.BearerAuth) (context.Context, error) {
+	startTime := time.Now()
+
+	if h.JWT == nil {
+		recordAuthFailure(ctx, AuthMethodJWT, authFailureDisabled)
+		return ctx, errors.New("error bearer tokens disabled")
 	}
 
-	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+	claims, err := h.JWT.Verify(ctx, auth.Token)
+	if err != nil {
+		h.logger.WarnContext(ctx, "authentication unsuccessful",
+			"error", err,
+			"duration_ms", time.Since(startTime).Milliseconds(),
+		)
+		recordAuthFailure(ctx, AuthMethodJWT, authFailureInvalid)
+		return ctx, fmt.Errorf("error credentials invalid: %w", err)
+	}
+
+	name := claims.Email
+	if name == "" {
+		name = claims.Subject
+	}
+
+	principal := Principal{Name: name, Method: AuthMethodJWT, Grants: claims.Grants}
+	if err := h.authorize(ctx, operationName, principal); err != nil {
+		return ctx, err
+	}
+
+	h.logger.InfoContext(ctx, "authenticated successfully",
+		"operation", operationName,
+		"subject", claims.Subject,
+		"email", claims.Email,
+		"groups", claims.Groups,
+		"duration_ms", time.Since(startTime).Milliseconds(),
+	)
+
+	return context.WithValue(ctx, userContextKey, principal), nil
+}
+
+// HandleMutualTLS authenticates by the client certificate verified in the
+// TLS handshake. ogen runs it after the other schemes and before the request
+// body is decoded, so a request without any credentials is rejected before
+// its upload is read. It is skipped when another scheme already
+// authenticated the request.
+func (h *SecurityHandler) HandleMutualTLS(ctx context.Context, operationName fileupload.OperationName, req *http.Request) (context.Context, error) {
+	if _, ok := PrincipalFromContext(ctx); ok {
+		return ctx, ogenerrors.ErrSkipServerSecurity
+	}
+
+	if h.Certs == nil || req.TLS == nil || len(req.TLS.VerifiedChains) == 0 {
+		recordAuthFailure(ctx, authMethodNone, authFailureMissing)
+		return ctx, ogenerrors.ErrSkipServerSecurity
+	}
+
+	return h.handleClientCert(ctx, operationName, req.TLS.VerifiedChains[0][0])
+}
+
+// handleClientCert maps a client certificate already verified by the TLS
+// handshake onto a principal.
+func (h *SecurityHandler) handleClientCert(ctx context.Context, operationName fileupload.OperationName, cert *x509.Certificate) (context.Context, error) {
+	name, grants := h.Certs.Identify(cert)
+	if name == "" {
+		h.logger.WarnContext(ctx, "authentication unsuccessful",
+			"serial", cert.SerialNumber.String(),
+			"error", "client certificate has no subject or SAN",
+		)
+		recordAuthFailure(ctx, AuthMethodMTLS, authFailureNoIdentity)
+		return ctx, errors.New("error client certificate has no identity")
+	}
+
+	principal := Principal{Name: name, Method: AuthMethodMTLS, Grants: grants}
+	if err := h.authorize(ctx, operationName, principal); err != nil {
+		return ctx, err
+	}
+
+	h.logger.InfoContext(ctx, "authenticated successfully",
+		"operation", operationName,
+		"certificate", name,
+		"issuer", cert.Issuer.String(),
+		"serial", cert.SerialNumber.String(),
+	)
+
+	return context.WithValue(ctx, userContextKey, principal), nil
+}
+
+// checkLockout rejects attempts while the username or client IP is locked out.
+func (h *SecurityHandler) checkLockout(ctx context.Context, username, clientIP string, keys []string) error {
+	if h.Lockout == nil {
+		return nil
+	}
+	return h.lockoutResult(ctx, h.Lockout.Check(ctx, keys...), username, clientIP)
+}
+
+// failLockout counts a failed attempt and returns the lockout it triggers, if any.
+func (h *SecurityHandler) failLockout(ctx context.Context, username, clientIP string, keys []string) error {
+	if h.Lockout == nil {
+		return nil
+	}
+	return h.lockoutResult(ctx, h.Lockout.Fail(ctx, keys...), username, clientIP)
+}
+
+// lockoutResult logs a lockout and returns it. Store errors are logged and
+// ignored so an unavailable store does not block every login.
+func (h *SecurityHandler) lockoutResult(ctx context.Context, err error, username, clientIP string) error {
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, lockout.ErrLocked) {
+		h.logger.ErrorContext(ctx, "lockout store unavailable", "username", username, "error", err)
+		return nil
+	}
+	recordAuthFailure(ctx, AuthMethodBasic, authFailureLockedOut)
+	h.logger.WarnContext(ctx, "authentication locked out",
+		"username", username,
+		"client_ip", clientIP,
+		"error", err,
+	)
+	return err
+}
+
+// authorize checks that the principal holds the operation's permission under
+// at least one prefix. Prefix scoping is enforced by the handler once the
+// object key is known. Server-wide operations need admin on the whole bucket,
+// so a tenant admin such as admin:partner-a/ cannot call them.
+func (h *SecurityHandler) authorize(ctx context.Context, operationName fileupload.OperationName, principal Principal) error {
+	audit.Update(ctx, func(e *audit.Event) {
+		e.Principal = principal.Name
+		e.AuthMethod = principal.Method
+	})
+
+	permission, ok := operationPermissions[operationName]
+	allowed := ok && principal.Grants.AllowsAny(permission)
+	if !ok {
+		permission = credentials.PermissionAdmin
+		allowed = principal.Grants.Allows(permission, "")
+	}
+
+	if !allowed {
+		h.logger.WarnContext(ctx, "authorization denied",
+			"operation", operationName,
+			"username", principal.Name,
+			"permission", permission,
+		)
+		recordAuthFailure(ctx, principal.Method, authFailureForbidden)
+		return fmt.Errorf("%w: %s requires %s", ErrForbidden, operationName, permission)
+	}
+	return nil
 }
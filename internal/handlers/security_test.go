@@ -1,14 +1,34 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
 	"io"
 	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ogen-go/ogen/ogenerrors"
 	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
 	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/lockout"
 	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
 )
 
 func newDiscardLogger() *slog.Logger {
@@ -57,3 +77,281 @@ func TestHandleBasicAuthWithPlaintextPassword(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestHandleBasicAuthStoresPrincipal(t *testing.T) {
+	handler := NewSecurityHandler(newDiscardLogger(), "testuser", "testpass")
+
+	ctx, err := handler.HandleBasicAuth(context.Background(), fileupload.UploadFileOperation, fileupload.BasicAuth{
+		Username: "testuser",
+		Password: "testpass",
+	})
+	require.NoError(t, err)
+
+	principal, ok := PrincipalFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "testuser", principal.Name)
+	require.True(t, principal.Grants.AllowsAny(credentials.PermissionAdmin))
+}
+
+func TestHandleBasicAuthForbidsMissingPermission(t *testing.T) {
+	users, err := credentials.NewStatic(credentials.User{
+		Username: "reader",
+		Password: "testpass",
+		Roles:    []string{"read", "list"},
+	})
+	require.NoError(t, err)
+	handler := NewStoreSecurityHandler(newDiscardLogger(), SecurityConfig{Users: users})
+
+	_, err = handler.HandleBasicAuth(context.Background(), fileupload.UploadFileOperation, fileupload.BasicAuth{
+		Username: "reader",
+		Password: "testpass",
+	})
+	require.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestServerWideOperationsRequireUnscopedAdmin(t *testing.T) {
+	users, err := credentials.NewStatic(
+		credentials.User{Username: "tenant-admin", Password: "testpass", Roles: []string{"admin:partner-a/"}},
+		credentials.User{Username: "admin", Password: "testpass", Roles: []string{"admin"}},
+	)
+	require.NoError(t, err)
+	handler := NewStoreSecurityHandler(newDiscardLogger(), SecurityConfig{Users: users})
+	uploads := NewUploadHandler(newDiscardLogger(), gcsClientZero())
+
+	for _, operation := range []fileupload.OperationName{
+		fileupload.SetLogLevelOperation,
+		fileupload.GetLogLevelOperation,
+		fileupload.ListWebhookDeadLettersOperation,
+	} {
+		_, err := handler.HandleBasicAuth(context.Background(), operation, fileupload.BasicAuth{Username: "tenant-admin", Password: "testpass"})
+		require.ErrorIs(t, err, ErrForbidden, operation)
+		require.Equal(t, http.StatusForbidden, uploads.NewError(context.Background(), err).StatusCode)
+
+		_, err = handler.HandleBasicAuth(context.Background(), operation, fileupload.BasicAuth{Username: "admin", Password: "testpass"})
+		require.NoError(t, err, operation)
+	}
+
+	// Object operations stay open to the tenant admin and are scoped by the handler.
+	_, err = handler.HandleBasicAuth(context.Background(), fileupload.DeleteFileOperation, fileupload.BasicAuth{Username: "tenant-admin", Password: "testpass"})
+	require.NoError(t, err)
+}
+
+func newAPIKeyHandler(t *testing.T, roles []string) (*SecurityHandler, string) {
+	t.Helper()
+	key, record, err := credentials.GenerateAPIKey("etl", roles, time.Time{})
+	require.NoError(t, err)
+
+	raw, err := yaml.Marshal(map[string]any{"keys": []credentials.APIKey{record}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+
+	apiKeys, err := credentials.LoadAPIKeys(path, newDiscardLogger())
+	require.NoError(t, err)
+
+	return NewStoreSecurityHandler(newDiscardLogger(), SecurityConfig{APIKeys: apiKeys}), key
+}
+
+func TestHandleApiKeyAuth(t *testing.T) {
+	handler, key := newAPIKeyHandler(t, []string{"upload"})
+
+	ctx, err := handler.HandleApiKeyAuth(context.Background(), fileupload.UploadFileOperation, fileupload.ApiKeyAuth{APIKey: key})
+	require.NoError(t, err)
+
+	principal, ok := PrincipalFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "etl", principal.Name)
+	require.Equal(t, AuthMethodAPIKey, principal.Method)
+}
+
+func TestHandleApiKeyAuthLogsLastUse(t *testing.T) {
+	handler, key := newAPIKeyHandler(t, []string{"upload"})
+	buf := &bytes.Buffer{}
+	handler.logger = slog.New(slog.NewJSONHandler(buf, nil))
+
+	_, err := handler.HandleApiKeyAuth(context.Background(), fileupload.UploadFileOperation, fileupload.ApiKeyAuth{APIKey: key})
+	require.NoError(t, err)
+	require.NotContains(t, buf.String(), "key_last_used", "first use since start")
+
+	buf.Reset()
+	_, err = handler.HandleApiKeyAuth(context.Background(), fileupload.UploadFileOperation, fileupload.ApiKeyAuth{APIKey: key})
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `"key_last_used":"`)
+}
+
+func TestHandleApiKeyAuthRejectsUnknownKey(t *testing.T) {
+	handler, _ := newAPIKeyHandler(t, []string{"upload"})
+
+	_, err := handler.HandleApiKeyAuth(context.Background(), fileupload.UploadFileOperation, fileupload.ApiKeyAuth{APIKey: "fuk_00000000_nope"})
+	require.Error(t, err)
+}
+
+func TestHandleBasicAuthDisabledWithoutUsers(t *testing.T) {
+	handler, _ := newAPIKeyHandler(t, []string{"upload"})
+
+	_, err := handler.HandleBasicAuth(context.Background(), fileupload.UploadFileOperation, fileupload.BasicAuth{
+		Username: "testuser",
+		Password: "testpass",
+	})
+	require.Error(t, err)
+}
+
+func TestHandleBearerAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	verifier, err := credentials.NewJWTVerifier(credentials.JWTConfig{
+		JWKSURL:    jwks.URL,
+		Issuer:     "https://issuer.example.com",
+		Audiences:  []string{"file-upload"},
+		GroupRoles: map[string][]string{"partners": {"upload"}},
+	})
+	require.NoError(t, err)
+	handler := NewStoreSecurityHandler(newDiscardLogger(), SecurityConfig{JWT: verifier})
+
+	sign := func(groups []string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":    "https://issuer.example.com",
+			"aud":    "file-upload",
+			"sub":    "user-1",
+			"email":  "user@example.com",
+			"groups": groups,
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	ctx, err := handler.HandleBearerAuth(context.Background(), fileupload.UploadFileOperation, fileupload.BearerAuth{Token: sign([]string{"partners"})})
+	require.NoError(t, err)
+
+	principal, ok := PrincipalFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "user@example.com", principal.Name)
+	require.Equal(t, AuthMethodJWT, principal.Method)
+
+	_, err = handler.HandleBearerAuth(context.Background(), fileupload.UploadFileOperation, fileupload.BearerAuth{Token: sign([]string{"other"})})
+	require.ErrorIs(t, err, ErrForbidden)
+
+	_, err = handler.HandleBearerAuth(context.Background(), fileupload.UploadFileOperation, fileupload.BearerAuth{Token: "not-a-token"})
+	require.Error(t, err)
+}
+
+func clientCertRequest(cert *x509.Certificate) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	if cert != nil {
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	return req
+}
+
+func TestHandleMutualTLS(t *testing.T) {
+	certs, err := credentials.NewClientCertMapper(map[string][]string{"partner-a": {"upload"}})
+	require.NoError(t, err)
+	handler := NewStoreSecurityHandler(newDiscardLogger(), SecurityConfig{Certs: certs})
+
+	ctx, err := handler.HandleMutualTLS(context.Background(), fileupload.UploadFileOperation, clientCertRequest(&x509.Certificate{Subject: pkix.Name{CommonName: "partner-a"}}))
+	require.NoError(t, err)
+	principal, ok := PrincipalFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "partner-a", principal.Name)
+	require.Equal(t, AuthMethodMTLS, principal.Method)
+
+	_, err = handler.HandleMutualTLS(context.Background(), fileupload.UploadFileOperation, clientCertRequest(&x509.Certificate{Subject: pkix.Name{CommonName: "partner-b"}}))
+	require.ErrorIs(t, err, ErrForbidden)
+
+	_, err = handler.HandleMutualTLS(context.Background(), fileupload.UploadFileOperation, clientCertRequest(nil))
+	require.ErrorIs(t, err, ogenerrors.ErrSkipServerSecurity)
+}
+
+func TestHandleMutualTLSKeepsExistingPrincipal(t *testing.T) {
+	handler := NewSecurityHandler(newDiscardLogger(), "testuser", "testpass")
+
+	ctx, err := handler.HandleBasicAuth(context.Background(), fileupload.UploadFileOperation, fileupload.BasicAuth{
+		Username: "testuser",
+		Password: "testpass",
+	})
+	require.NoError(t, err)
+
+	_, err = handler.HandleMutualTLS(ctx, fileupload.UploadFileOperation, clientCertRequest(nil))
+	require.ErrorIs(t, err, ogenerrors.ErrSkipServerSecurity)
+}
+
+// trackingReader records whether the server read the request body.
+type trackingReader struct {
+	io.Reader
+	read bool
+}
+
+func (r *trackingReader) Read(p []byte) (int, error) {
+	r.read = true
+	return r.Reader.Read(p)
+}
+
+func TestAnonymousUploadRejectedBeforeBodyIsRead(t *testing.T) {
+	certs, err := credentials.NewClientCertMapper(nil)
+	require.NoError(t, err)
+	sec := NewStoreSecurityHandler(newDiscardLogger(), SecurityConfig{
+		Users: credentials.NewSingleUser("testuser", "testpass"),
+		Certs: certs,
+	})
+	server, err := fileupload.NewServer(NewUploadHandler(newDiscardLogger(), gcsClientZero()), sec)
+	require.NoError(t, err)
+
+	body := &trackingReader{Reader: strings.NewReader("--x\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a.csv\"\r\n\r\na,b\r\n--x--\r\n")}
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.False(t, body.read, "the upload must not be read before authentication")
+}
+
+func TestHandleBasicAuthLocksOutAfterRepeatedFailures(t *testing.T) {
+	guard, err := lockout.New(lockout.Config{Threshold: 3, BaseDelay: time.Minute}, lockout.NewMemoryStore())
+	require.NoError(t, err)
+
+	handler := NewStoreSecurityHandler(newDiscardLogger(), SecurityConfig{
+		Users:   credentials.NewSingleUser("testuser", "testpass"),
+		Lockout: guard,
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	req.RemoteAddr = "203.0.113.7:51000"
+	var ctx context.Context
+	WithClientIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { ctx = r.Context() }), nil).ServeHTTP(rec, req)
+
+	ip, ok := ClientIPFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "203.0.113.7", ip)
+
+	wrong := fileupload.BasicAuth{Username: "testuser", Password: "wrong"}
+	for range 2 {
+		_, err := handler.HandleBasicAuth(ctx, fileupload.UploadFileOperation, wrong)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, lockout.ErrLocked)
+	}
+
+	_, err = handler.HandleBasicAuth(ctx, fileupload.UploadFileOperation, wrong)
+	require.ErrorIs(t, err, lockout.ErrLocked)
+
+	// The correct password is refused while locked out.
+	_, err = handler.HandleBasicAuth(ctx, fileupload.UploadFileOperation, fileupload.BasicAuth{Username: "testuser", Password: "testpass"})
+	var locked *lockout.LockedError
+	require.ErrorAs(t, err, &locked)
+	require.Positive(t, locked.RetryAfter)
+}
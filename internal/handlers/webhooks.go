@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+)
+
+// ListWebhookDeadLetters returns the deliveries that ran out of attempts.
+// The list is empty when no webhooks are configured.
+func (h *UploadHandler) ListWebhookDeadLetters(ctx context.Context) (*fileupload.WebhookDeliveryList, error) {
+	list := &fileupload.WebhookDeliveryList{Deliveries: []fileupload.WebhookDelivery{}}
+	if h.Webhooks == nil {
+		return list, nil
+	}
+
+	deliveries, err := h.Webhooks.DeadLetters()
+	if err != nil {
+		return nil, fmt.Errorf("listing webhook dead letters: %w", err)
+	}
+	for _, d := range deliveries {
+		delivery := fileupload.WebhookDelivery{
+			ID:           d.ID,
+			Subscription: d.Subscription,
+			Attempts:     d.Attempts,
+		}
+		if d.Event != nil {
+			delivery.Event = *d.Event
+		}
+		if !d.LastAttempt.IsZero() {
+			delivery.LastAttempt = fileupload.NewOptDateTime(d.LastAttempt)
+		}
+		if d.LastError != "" {
+			delivery.LastError = fileupload.NewOptString(d.LastError)
+		}
+		list.Deliveries = append(list.Deliveries, delivery)
+	}
+	return list, nil
+}
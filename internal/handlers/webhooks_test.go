@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/credentials"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/webhook"
+)
+
+func TestListWebhookDeadLettersWithoutWebhooks(t *testing.T) {
+	handler := NewUploadHandler(newDiscardLogger(), gcsClientZero())
+
+	res, err := handler.ListWebhookDeadLetters(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, res.Deliveries)
+	require.Empty(t, res.Deliveries)
+}
+
+func TestUploadFileDeliversWebhook(t *testing.T) {
+	received := make(chan fileupload.UploadEvent, 2)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify([]byte("secret"), r.Header.Get(webhook.SignatureHeader), body, time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event fileupload.UploadEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- event
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	dispatcher, err := webhook.New(webhook.Config{QueueDir: t.TempDir()}, []webhook.Subscription{
+		{Name: "partner-a", URL: receiver.URL, Secret: "secret", Prefixes: []string{"partner-a/"}},
+		{Name: "partner-b", URL: receiver.URL, Secret: "secret", Prefixes: []string{"partner-b/"}},
+	}, newDiscardLogger())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dispatcher.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	bucket, _ := newFakeGcsClient(t)
+	handler := NewUploadHandler(newDiscardLogger(), bucket)
+	handler.Webhooks = dispatcher
+
+	req := uploadRequest("report.csv", "id,amount\n1,2\n")
+	req.Prefix = fileupload.NewOptString("partner-a")
+	_, err = handler.UploadFile(withPrincipal(ctx, "partner-a", credentials.Grant{Permission: credentials.PermissionUpload, Prefix: "partner-a/"}), req)
+	require.NoError(t, err)
+
+	select {
+	case event := <-received:
+		require.Equal(t, fileupload.UploadEventTypeUploadCompleted, event.Type)
+		require.Equal(t, "partner-a", event.Uploader)
+		require.Equal(t, "partner-a/report.csv", event.Upload.Filename)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+	require.Never(t, func() bool { return len(received) > 0 }, 100*time.Millisecond, 10*time.Millisecond,
+		"only the matching subscription is notified")
+
+	res, err := handler.ListWebhookDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Empty(t, res.Deliveries)
+}
@@ -0,0 +1,196 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether a dependency is usable.
+type Check func(ctx context.Context) error
+
+// Config controls how often checks run.
+type Config struct {
+	// CacheTTL is how long a check result is reused, so frequent probes do
+	// not hit dependencies on every request.
+	CacheTTL time.Duration
+	// Timeout bounds each check.
+	Timeout time.Duration
+	// Logger receives the errors of failing checks, which are not part of
+	// the unauthenticated response. Nil discards them.
+	Logger *slog.Logger
+}
+
+// CheckResult is the outcome of one check in a readiness response. Only the
+// status is served, since the probes are unauthenticated.
+type CheckResult struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"-"`
+	Optional  bool      `json:"-"`
+	CheckedAt time.Time `json:"-"`
+}
+
+// Response is the body of the health endpoints.
+type Response struct {
+	Status string                 `json:"status"`
+	Uptime string                 `json:"uptime,omitempty"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+const (
+	StatusOK           = "ok"
+	StatusError        = "error"
+	StatusReady        = "ready"
+	StatusNotReady     = "not ready"
+	StatusShuttingDown = "shutting down"
+)
+
+type namedCheck struct {
+	name     string
+	check    Check
+	optional bool
+}
+
+// Checker serves liveness and readiness probes.
+type Checker struct {
+	cfg     Config
+	started time.Time
+	now     func() time.Time
+
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+
+	mu    sync.Mutex
+	cache map[string]CheckResult
+}
+
+// New creates a checker. Zero config values fall back to a 30s cache and a
+// 5s timeout.
+func New(cfg Config) *Checker {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.New(slog.DiscardHandler)
+	}
+	return &Checker{
+		cfg:     cfg,
+		started: time.Now(),
+		now:     time.Now,
+		cache:   map[string]CheckResult{},
+	}
+}
+
+// Add registers a check that must pass for the service to be ready.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// AddOptional registers a check that is reported but does not affect
+// readiness.
+func (c *Checker) AddOptional(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check, optional: true})
+}
+
+// ShutDown marks the service as not ready so load balancers stop sending
+// traffic while in-flight requests finish.
+func (c *Checker) ShutDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Liveness reports that the process is up.
+func (c *Checker) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Response{
+			Status: StatusOK,
+			Uptime: c.now().Sub(c.started).Round(time.Second).String(),
+		})
+	})
+}
+
+// Readiness runs the checks and responds 503 if a required one fails or the
+// service is shutting down.
+func (c *Checker) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, ready := c.Ready(r.Context())
+		status := http.StatusOK
+		if !ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, res)
+	})
+}
+
+// Ready runs the checks, reusing results younger than the cache TTL.
+func (c *Checker) Ready(ctx context.Context) (Response, bool) {
+	res := Response{Status: StatusReady, Checks: make(map[string]CheckResult, len(c.checks))}
+	ready := true
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.run(ctx, nc)
+
+			mu.Lock()
+			defer mu.Unlock()
+			res.Checks[nc.name] = result
+			if result.Status != StatusOK && !nc.optional {
+				ready = false
+			}
+		}()
+	}
+	wg.Wait()
+
+	switch {
+	case c.shuttingDown.Load():
+		res.Status = StatusShuttingDown
+		ready = false
+	case !ready:
+		res.Status = StatusNotReady
+	}
+	return res, ready
+}
+
+func (c *Checker) run(ctx context.Context, nc namedCheck) CheckResult {
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.cache[nc.name]
+	c.mu.Unlock()
+	if ok && now.Sub(cached.CheckedAt) < c.cfg.CacheTTL {
+		return cached
+	}
+
+	// The result is cached for other probes, so a probe that gives up early
+	// must not cancel the check and cache the cancellation as a failure.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.cfg.Timeout)
+	defer cancel()
+
+	result := CheckResult{Status: StatusOK, Optional: nc.optional, CheckedAt: now}
+	if err := nc.check(ctx); err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+		c.cfg.Logger.WarnContext(ctx, "health check failed", "check", nc.name, "optional", nc.optional, "error", err)
+	}
+
+	c.mu.Lock()
+	c.cache[nc.name] = result
+	c.mu.Unlock()
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, res Response) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, h http.Handler) (int, Response) {
+	t.Helper()
+	code, body := getBody(t, h)
+
+	var res Response
+	require.NoError(t, json.Unmarshal([]byte(body), &res))
+	return code, res
+}
+
+func getBody(t *testing.T, h http.Handler) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestLiveness(t *testing.T) {
+	c := New(Config{})
+	c.Add("broken", func(ctx context.Context) error { return errors.New("down") })
+
+	code, res := get(t, c.Liveness())
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, StatusOK, res.Status)
+}
+
+func TestReadiness(t *testing.T) {
+	var gcsErr error
+	c := New(Config{CacheTTL: time.Nanosecond})
+	c.Add("gcs", func(ctx context.Context) error { return gcsErr })
+	c.AddOptional("tracing", func(ctx context.Context) error { return errors.New("exporter unavailable") })
+
+	code, res := get(t, c.Readiness())
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, StatusReady, res.Status)
+	require.Equal(t, StatusOK, res.Checks["gcs"].Status)
+	require.Equal(t, StatusError, res.Checks["tracing"].Status)
+
+	gcsErr = errors.New("permission denied on uploads")
+	code, res = get(t, c.Readiness())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, StatusNotReady, res.Status)
+	require.Equal(t, StatusError, res.Checks["gcs"].Status)
+
+	_, body := getBody(t, c.Readiness())
+	require.JSONEq(t, `{"status":"not ready","checks":{"gcs":{"status":"error"},"tracing":{"status":"error"}}}`, body)
+
+	ready, _ := c.Ready(context.Background())
+	require.Equal(t, "permission denied on uploads", ready.Checks["gcs"].Error)
+	require.True(t, ready.Checks["tracing"].Optional)
+}
+
+func TestReadinessIgnoresProbeCancellation(t *testing.T) {
+	c := New(Config{CacheTTL: time.Minute})
+	c.Add("gcs", func(ctx context.Context) error { return ctx.Err() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ready := c.Ready(ctx)
+	require.True(t, ready)
+}
+
+func TestReadinessCachesResults(t *testing.T) {
+	calls := 0
+	c := New(Config{CacheTTL: time.Minute})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.Add("gcs", func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+
+	for range 3 {
+		_, ready := c.Ready(context.Background())
+		require.True(t, ready)
+	}
+	require.Equal(t, 1, calls)
+
+	now = now.Add(2 * time.Minute)
+	c.Ready(context.Background())
+	require.Equal(t, 2, calls)
+}
+
+func TestReadinessShuttingDown(t *testing.T) {
+	c := New(Config{})
+	c.Add("gcs", func(ctx context.Context) error { return nil })
+	c.ShutDown()
+
+	code, res := get(t, c.Readiness())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, StatusShuttingDown, res.Status)
+
+	code, _ = get(t, c.Liveness())
+	require.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,178 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "gitlab.com/totalprocessing/file-upload/internal/lockout"
+
+var ErrLocked = errors.New("too many failed attempts")
+
+// LockedError is returned while a key is locked out.
+type LockedError struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%v: %s locked for %s", ErrLocked, e.Key, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+// Record is the failure history of a single key.
+type Record struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Store persists records. Updates are atomic so that concurrent failures for
+// the same key are all counted. A record expires once none of its updates'
+// ttls is left.
+type Store interface {
+	Get(ctx context.Context, key string) (Record, error)
+	// AddFailure increments the key's failures and returns the new count.
+	AddFailure(ctx context.Context, key string, ttl time.Duration) (int, error)
+	// Lock moves the key's LockedUntil forward to until if it is earlier.
+	Lock(ctx context.Context, key string, until time.Time, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Config controls when keys are locked and for how long.
+type Config struct {
+	// Threshold is the number of consecutive failures before the first lockout.
+	Threshold int
+	// BaseDelay is the first lockout; each further failure doubles it.
+	BaseDelay time.Duration
+	// MaxDelay caps the lockout.
+	MaxDelay time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+// Guard tracks failed attempts and locks keys out with exponentially
+// increasing delays.
+type Guard struct {
+	cfg      Config
+	store    Store
+	now      func() time.Time
+	lockouts metric.Int64Counter
+}
+
+// New creates a guard. Zero config values fall back to 5 failures, 1s base
+// delay, 15m max delay and a 15m window.
+func New(cfg Config, store Store) (*Guard, error) {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 5
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = time.Second
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 15 * time.Minute
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 15 * time.Minute
+	}
+
+	lockouts, err := otel.Meter(meterName).Int64Counter("auth.lockouts",
+		metric.WithDescription("Number of times a username or client IP was locked out"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating lockout counter: %w", err)
+	}
+
+	return &Guard{
+		cfg:      cfg,
+		store:    store,
+		now:      time.Now,
+		lockouts: lockouts,
+	}, nil
+}
+
+// Check returns a *LockedError if any of the keys is locked out.
+func (g *Guard) Check(ctx context.Context, keys ...string) error {
+	now := g.now()
+	var locked *LockedError
+	for _, key := range keys {
+		record, err := g.store.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("reading lockout record: %w", err)
+		}
+		if wait := record.LockedUntil.Sub(now); wait > 0 && (locked == nil || wait > locked.RetryAfter) {
+			locked = &LockedError{Key: key, RetryAfter: wait}
+		}
+	}
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+// Fail records a failed attempt for each key, locking out keys that reach the
+// threshold. It returns a *LockedError if any key is now locked.
+func (g *Guard) Fail(ctx context.Context, keys ...string) error {
+	now := g.now()
+	var locked *LockedError
+	for _, key := range keys {
+		failures, err := g.store.AddFailure(ctx, key, g.cfg.Window)
+		if err != nil {
+			return fmt.Errorf("recording lockout failure: %w", err)
+		}
+		if failures < g.cfg.Threshold {
+			continue
+		}
+
+		delay := g.delay(failures - g.cfg.Threshold)
+		if err := g.store.Lock(ctx, key, now.Add(delay), max(delay, g.cfg.Window)); err != nil {
+			return fmt.Errorf("writing lockout record: %w", err)
+		}
+		g.lockouts.Add(ctx, 1, metric.WithAttributes(attribute.String("key_type", keyType(key))))
+		if locked == nil || delay > locked.RetryAfter {
+			locked = &LockedError{Key: key, RetryAfter: delay}
+		}
+	}
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+// Succeed clears the failure history of the keys.
+func (g *Guard) Succeed(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := g.store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("clearing lockout record: %w", err)
+		}
+	}
+	return nil
+}
+
+func (g *Guard) delay(step int) time.Duration {
+	delay := g.cfg.BaseDelay
+	for i := 0; i < step && delay < g.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, g.cfg.MaxDelay)
+}
+
+// UserKey and IPKey namespace the keys tracked for usernames and client IPs.
+func UserKey(username string) string { return "user:" + username }
+func IPKey(ip string) string         { return "ip:" + ip }
+
+func keyType(key string) string {
+	if kind, _, ok := strings.Cut(key, ":"); ok {
+		return kind
+	}
+	return "other"
+}
@@ -0,0 +1,140 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestGuard(t *testing.T, cfg Config) (*Guard, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+
+	guard, err := New(cfg, store)
+	require.NoError(t, err)
+	guard.now = clock.Now
+	return guard, clock
+}
+
+func TestFailLocksOutAtThreshold(t *testing.T) {
+	guard, _ := newTestGuard(t, Config{Threshold: 3, BaseDelay: time.Second})
+	ctx := context.Background()
+
+	require.NoError(t, guard.Fail(ctx, UserKey("a")))
+	require.NoError(t, guard.Fail(ctx, UserKey("a")))
+	require.NoError(t, guard.Check(ctx, UserKey("a")))
+
+	err := guard.Fail(ctx, UserKey("a"))
+	var locked *LockedError
+	require.ErrorAs(t, err, &locked)
+	require.Equal(t, time.Second, locked.RetryAfter)
+	require.ErrorIs(t, guard.Check(ctx, UserKey("a")), ErrLocked)
+	require.NoError(t, guard.Check(ctx, UserKey("b")))
+}
+
+func TestLockoutDoublesUpToMax(t *testing.T) {
+	guard, clock := newTestGuard(t, Config{Threshold: 1, BaseDelay: time.Second, MaxDelay: 5 * time.Second})
+	ctx := context.Background()
+
+	var locked *LockedError
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		require.ErrorAs(t, guard.Fail(ctx, IPKey("10.0.0.1")), &locked)
+		require.Equal(t, want, locked.RetryAfter)
+	}
+
+	clock.now = clock.now.Add(5 * time.Second)
+	require.NoError(t, guard.Check(ctx, IPKey("10.0.0.1")))
+}
+
+func TestCheckReportsLongestLockout(t *testing.T) {
+	guard, _ := newTestGuard(t, Config{Threshold: 1, BaseDelay: time.Second})
+	ctx := context.Background()
+
+	_ = guard.Fail(ctx, UserKey("a"))
+	_ = guard.Fail(ctx, IPKey("10.0.0.1"))
+	_ = guard.Fail(ctx, IPKey("10.0.0.1"))
+
+	var locked *LockedError
+	require.ErrorAs(t, guard.Check(ctx, UserKey("a"), IPKey("10.0.0.1")), &locked)
+	require.Equal(t, IPKey("10.0.0.1"), locked.Key)
+	require.Equal(t, 2*time.Second, locked.RetryAfter)
+}
+
+func TestFailuresExpireAfterWindow(t *testing.T) {
+	guard, clock := newTestGuard(t, Config{Threshold: 2, Window: time.Minute})
+	ctx := context.Background()
+
+	require.NoError(t, guard.Fail(ctx, UserKey("a")))
+	clock.now = clock.now.Add(2 * time.Minute)
+	require.NoError(t, guard.Fail(ctx, UserKey("a")))
+}
+
+func TestSucceedClearsFailures(t *testing.T) {
+	guard, _ := newTestGuard(t, Config{Threshold: 2})
+	ctx := context.Background()
+
+	require.NoError(t, guard.Fail(ctx, UserKey("a")))
+	require.NoError(t, guard.Succeed(ctx, UserKey("a")))
+	require.NoError(t, guard.Fail(ctx, UserKey("a")))
+}
+
+func TestConcurrentFailuresLockOut(t *testing.T) {
+	server := miniredis.RunT(t)
+	redisStore, err := NewRedisStore("redis://" + server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = redisStore.Close() })
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "redis": redisStore} {
+		t.Run(name, func(t *testing.T) {
+			const attempts = 50
+			guard, err := New(Config{Threshold: attempts, BaseDelay: time.Minute}, store)
+			require.NoError(t, err)
+			ctx := context.Background()
+
+			var wg sync.WaitGroup
+			for range attempts {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = guard.Fail(ctx, UserKey("victim"))
+				}()
+			}
+			wg.Wait()
+
+			record, err := store.Get(ctx, UserKey("victim"))
+			require.NoError(t, err)
+			require.Equal(t, attempts, record.Failures)
+			require.ErrorIs(t, guard.Check(ctx, UserKey("victim")), ErrLocked)
+		})
+	}
+}
+
+func TestRedisStoreOnlyExtendsLocks(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := NewRedisStore("redis://" + server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	until := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	require.NoError(t, store.Lock(ctx, UserKey("a"), until, time.Hour))
+	require.NoError(t, store.Lock(ctx, UserKey("a"), until.Add(-time.Minute), time.Minute))
+	failures, err := store.AddFailure(ctx, UserKey("a"), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, failures)
+
+	record, err := store.Get(ctx, UserKey("a"))
+	require.NoError(t, err)
+	require.True(t, until.Equal(record.LockedUntil))
+	require.Equal(t, time.Hour, server.TTL("file-upload:lockout:"+UserKey("a")))
+}
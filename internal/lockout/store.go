@@ -0,0 +1,171 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryStore keeps records in process memory. Each instance tracks failures
+// separately; use RedisStore to share them between instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	records map[string]memoryRecord
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		records: map[string]memoryRecord{},
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.live(key, s.now()).Record, nil
+}
+
+func (s *MemoryStore) AddFailure(_ context.Context, key string, ttl time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	record := s.live(key, now)
+	record.Failures++
+	s.keep(key, record, now, ttl)
+	return record.Failures, nil
+}
+
+func (s *MemoryStore) Lock(_ context.Context, key string, until time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	record := s.live(key, now)
+	if until.After(record.LockedUntil) {
+		record.LockedUntil = until
+	}
+	s.keep(key, record, now, ttl)
+	return nil
+}
+
+// live returns the unexpired record for key. The caller holds mu.
+func (s *MemoryStore) live(key string, now time.Time) memoryRecord {
+	record, ok := s.records[key]
+	if !ok || now.After(record.expiresAt) {
+		return memoryRecord{}
+	}
+	return record
+}
+
+// keep stores record for at least ttl. The caller holds mu.
+func (s *MemoryStore) keep(key string, record memoryRecord, now time.Time, ttl time.Duration) {
+	if expiresAt := now.Add(ttl); expiresAt.After(record.expiresAt) {
+		record.expiresAt = expiresAt
+	}
+	s.records[key] = record
+
+	// Sweep expired records so keys from one-off clients don't accumulate.
+	if len(s.records)%1024 == 0 {
+		for k, r := range s.records {
+			if now.After(r.expiresAt) {
+				delete(s.records, k)
+			}
+		}
+	}
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// RedisStore keeps records in Redis, or any server speaking its protocol,
+// so lockouts apply across instances.
+type RedisStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// NewRedisStore connects to the server at url, e.g. redis://localhost:6379/0.
+func NewRedisStore(url string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parsing redis url: %w", err)
+	}
+	return &RedisStore{client: redis.NewClient(opts), keyPrefix: "file-upload:lockout:"}, nil
+}
+
+// Records are hashes of failures and lockedUntil (Unix milliseconds). The
+// scripts update them atomically and only ever extend their expiry.
+var (
+	addFailureScript = redis.NewScript(`
+local failures = redis.call('HINCRBY', KEYS[1], 'failures', 1)
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[1]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return failures`)
+
+	lockScript = redis.NewScript(`
+local current = tonumber(redis.call('HGET', KEYS[1], 'lockedUntil') or '0')
+if tonumber(ARGV[1]) > current then
+	redis.call('HSET', KEYS[1], 'lockedUntil', ARGV[1])
+end
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+func (s *RedisStore) Get(ctx context.Context, key string) (Record, error) {
+	fields, err := s.client.HGetAll(ctx, s.keyPrefix+key).Result()
+	if err != nil {
+		return Record{}, err
+	}
+
+	var record Record
+	if v, ok := fields["failures"]; ok {
+		if record.Failures, err = strconv.Atoi(v); err != nil {
+			return Record{}, fmt.Errorf("decoding record %s: %w", key, err)
+		}
+	}
+	if v, ok := fields["lockedUntil"]; ok {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Record{}, fmt.Errorf("decoding record %s: %w", key, err)
+		}
+		record.LockedUntil = time.UnixMilli(ms)
+	}
+	return record, nil
+}
+
+func (s *RedisStore) AddFailure(ctx context.Context, key string, ttl time.Duration) (int, error) {
+	return addFailureScript.Run(ctx, s.client, []string{s.keyPrefix + key}, ttl.Milliseconds()).Int()
+}
+
+func (s *RedisStore) Lock(ctx context.Context, key string, until time.Time, ttl time.Duration) error {
+	return lockScript.Run(ctx, s.client, []string{s.keyPrefix + key}, until.UnixMilli(), ttl.Milliseconds()).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.keyPrefix+key).Err()
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
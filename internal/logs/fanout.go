@@ -0,0 +1,50 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// Fanout returns a logger that writes every record to logger and to h, e.g.
+// to export logs over OpenTelemetry as well as to stdout.
+func Fanout(logger *slog.Logger, h slog.Handler) *slog.Logger {
+	return slog.New(fanoutHandler{logger.Handler(), h})
+}
+
+type fanoutHandler []slog.Handler
+
+func (f fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			err = errors.Join(err, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return err
+}
+
+func (f fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanoutHandler, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanoutHandler) WithGroup(name string) slog.Handler {
+	out := make(fanoutHandler, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
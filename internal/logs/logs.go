@@ -1,6 +1,7 @@
 package logs
 
 import (
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
@@ -12,30 +13,97 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// Log formats accepted by Config.Format.
+const (
+	FormatGCP     = "gcp"
+	FormatJSON    = "json"
+	FormatConsole = "console"
+	FormatLogfmt  = "logfmt"
+)
+
+// Config selects the format and level of a logger built by New.
+type Config struct {
+	Environment string
+	// Format is gcp, json, console or logfmt. Empty picks console for the
+	// local environment and gcp otherwise.
+	Format string
+	// Level is the lowest level logged, e.g. a *slog.LevelVar to change it
+	// at runtime. Nil uses DefaultLevel.
+	Level slog.Leveler
+	// GCPProject qualifies the trace name Cloud Logging links to.
+	GCPProject string
+}
+
 // NewLogger returns an slog logger backed by zap.
 // Production uses GCP-friendly zapdriver JSON, local uses zap console output.
-func NewLogger(environment string) *slog.Logger {
+// Records logged with a context carrying a span include its trace and span
+// ids; gcpProject qualifies the trace name Cloud Logging links to.
+func NewLogger(environment, gcpProject string) *slog.Logger {
+	logger, _ := New(os.Stdout, Config{Environment: environment, GCPProject: gcpProject})
+	return logger
+}
+
+// New returns a logger writing to w in the configured format.
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	level := cfg.Level
+	if level == nil {
+		level = DefaultLevel(cfg.Environment)
+	}
+
+	format := strings.ToLower(strings.TrimSpace(cfg.Format))
+	if format == "" {
+		format = FormatGCP
+		if isLocal(cfg.Environment) {
+			format = FormatConsole
+		}
+	}
+
+	switch format {
+	case FormatGCP:
+		return newGCPLogger(w, cfg.GCPProject, level), nil
+	case FormatConsole:
+		return newLocalLogger(w, level), nil
+	case FormatJSON:
+		return slog.New(newTraceHandler(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}), false, "")), nil
+	case FormatLogfmt:
+		return slog.New(newTraceHandler(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}), false, "")), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %q", cfg.Format)
+	}
+}
+
+// DefaultLevel is debug for the local environment and info otherwise.
+func DefaultLevel(environment string) slog.Level {
 	if isLocal(environment) {
-		return newLocalLogger(os.Stdout)
+		return slog.LevelDebug
 	}
-	return newGCPLogger(os.Stdout)
+	return slog.LevelInfo
 }
 
 func isLocal(environment string) bool {
 	return strings.EqualFold(strings.TrimSpace(environment), "local")
 }
 
-func newGCPLogger(w io.Writer) *slog.Logger {
+// zapLevel lets a zap core follow an slog level, which may change at runtime.
+// zapslog maps the slog debug, info, warn and error levels onto the zap ones,
+// which are 1 apart instead of 4.
+func zapLevel(level slog.Leveler) zapcore.LevelEnabler {
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return slog.Level(l)*4 >= level.Level()
+	})
+}
+
+func newGCPLogger(w io.Writer, project string, level slog.Leveler) *slog.Logger {
 	core := zapcore.NewCore(
 		zapcore.NewJSONEncoder(zapdriver.NewProductionEncoderConfig()),
 		zapcore.AddSync(w),
-		zap.InfoLevel,
+		zapLevel(level),
 	)
 	zl := zap.New(core)
-	return slog.New(zapslog.NewHandler(zl.Core()))
+	return slog.New(newTraceHandler(zapslog.NewHandler(zl.Core()), true, project))
 }
 
-func newLocalLogger(w io.Writer) *slog.Logger {
+func newLocalLogger(w io.Writer, level slog.Leveler) *slog.Logger {
 	enc := zap.NewDevelopmentEncoderConfig()
 	enc.TimeKey = ""
 	enc.CallerKey = ""
@@ -46,8 +114,8 @@ func newLocalLogger(w io.Writer) *slog.Logger {
 	core := zapcore.NewCore(
 		zapcore.NewConsoleEncoder(enc),
 		zapcore.AddSync(w),
-		zap.DebugLevel,
+		zapLevel(level),
 	)
 	zl := zap.New(core)
-	return slog.New(zapslog.NewHandler(zl.Core()))
+	return slog.New(newTraceHandler(zapslog.NewHandler(zl.Core()), false, ""))
 }
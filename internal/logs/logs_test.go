@@ -2,15 +2,18 @@ package logs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"log/slog"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestGCPLoggerFormatsSeverityAndMessage(t *testing.T) {
 	buf := &bytes.Buffer{}
-	logger := newGCPLogger(buf)
+	logger := newGCPLogger(buf, "", slog.LevelInfo)
 
 	logger.Warn("authentication failed", "component", "security")
 
@@ -38,7 +41,7 @@ func TestIsLocal(t *testing.T) {
 
 func TestLocalLoggerIsReadable(t *testing.T) {
 	buf := &bytes.Buffer{}
-	logger := newLocalLogger(buf)
+	logger := newLocalLogger(buf, slog.LevelDebug)
 
 	logger.Info("dev log", "component", "security")
 
@@ -46,3 +49,112 @@ func TestLocalLoggerIsReadable(t *testing.T) {
 	require.Contains(t, out, "dev log")
 	require.Contains(t, out, "component")
 }
+
+func spanContext(t *testing.T) context.Context {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func TestGCPLoggerAddsTraceFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := newGCPLogger(buf, "tp-playground", slog.LevelInfo).With("component", "security")
+
+	logger.InfoContext(spanContext(t), "authenticated")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "projects/tp-playground/traces/4bf92f3577b34da6a3ce929d0e0e4736", entry["logging.googleapis.com/trace"])
+	require.Equal(t, "00f067aa0ba902b7", entry["logging.googleapis.com/spanId"])
+	require.Equal(t, true, entry["logging.googleapis.com/trace_sampled"])
+	require.Equal(t, "security", entry["component"])
+}
+
+func TestGCPLoggerWithoutSpan(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := newGCPLogger(buf, "tp-playground", slog.LevelInfo)
+
+	logger.InfoContext(context.Background(), "no span")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	_, hasTrace := entry["logging.googleapis.com/trace"]
+	require.False(t, hasTrace)
+}
+
+func TestLocalLoggerAddsTraceIDs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := newLocalLogger(buf, slog.LevelDebug)
+
+	logger.InfoContext(spanContext(t), "dev log")
+
+	out := buf.String()
+	require.Contains(t, out, `"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"`)
+	require.Contains(t, out, `"span_id": "00f067aa0ba902b7"`)
+}
+
+func TestFanoutWritesToBothHandlers(t *testing.T) {
+	gcp := &bytes.Buffer{}
+	other := &bytes.Buffer{}
+	logger := Fanout(newGCPLogger(gcp, "", slog.LevelInfo), slog.NewJSONHandler(other, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	logger.With("component", "security").Info("info only")
+	require.Contains(t, gcp.String(), "info only")
+	require.Empty(t, other.String())
+
+	logger.With("component", "security").Warn("both")
+	require.Contains(t, gcp.String(), "both")
+	require.Contains(t, other.String(), `"component":"security"`)
+}
+
+func TestNewFormats(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(buf, Config{Format: FormatJSON})
+	require.NoError(t, err)
+	logger.InfoContext(spanContext(t), "json log")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "json log", entry["msg"])
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace_id"])
+
+	buf.Reset()
+	logger, err = New(buf, Config{Format: FormatLogfmt})
+	require.NoError(t, err)
+	logger.Info("logfmt log", "component", "security")
+	require.Contains(t, buf.String(), `msg="logfmt log" component=security`)
+
+	_, err = New(buf, Config{Format: "xml"})
+	require.Error(t, err)
+}
+
+func TestLevelChangesAtRuntime(t *testing.T) {
+	for _, format := range []string{FormatGCP, FormatConsole, FormatJSON, FormatLogfmt} {
+		t.Run(format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			level := &slog.LevelVar{}
+			level.Set(slog.LevelWarn)
+			logger, err := New(buf, Config{Format: format, Level: level})
+			require.NoError(t, err)
+
+			logger.Info("hidden")
+			require.Empty(t, buf.String())
+
+			level.Set(slog.LevelDebug)
+			logger.Debug("shown")
+			require.Contains(t, buf.String(), "shown")
+		})
+	}
+}
+
+func TestDefaultLevel(t *testing.T) {
+	require.Equal(t, slog.LevelDebug, DefaultLevel("local"))
+	require.Equal(t, slog.LevelInfo, DefaultLevel("production"))
+}
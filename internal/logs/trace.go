@@ -0,0 +1,62 @@
+package logs
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Cloud Logging fields that link an entry to a trace, as written by zapdriver.
+const (
+	gcpTraceKey        = "logging.googleapis.com/trace"
+	gcpSpanIDKey       = "logging.googleapis.com/spanId"
+	gcpTraceSampledKey = "logging.googleapis.com/trace_sampled"
+)
+
+// traceHandler adds the trace and span ids of the span in ctx to records
+// logged with the *Context methods.
+type traceHandler struct {
+	slog.Handler
+	// gcp selects the Cloud Logging fields instead of trace_id and span_id.
+	gcp     bool
+	project string
+}
+
+func newTraceHandler(h slog.Handler, gcp bool, project string) *traceHandler {
+	return &traceHandler{Handler: h, gcp: gcp, project: project}
+}
+
+func (h *traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	r = r.Clone()
+	if h.gcp {
+		traceID := sc.TraceID().String()
+		if h.project != "" {
+			traceID = "projects/" + h.project + "/traces/" + traceID
+		}
+		r.AddAttrs(
+			slog.String(gcpTraceKey, traceID),
+			slog.String(gcpSpanIDKey, sc.SpanID().String()),
+			slog.Bool(gcpTraceSampledKey, sc.IsSampled()),
+		)
+	} else {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return newTraceHandler(h.Handler.WithAttrs(attrs), h.gcp, h.project)
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return newTraceHandler(h.Handler.WithGroup(name), h.gcp, h.project)
+}
@@ -0,0 +1,323 @@
+package observability
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor
+)
+
+// Exporter names, matching the values of OTEL_TRACES_EXPORTER,
+// OTEL_METRICS_EXPORTER and OTEL_LOGS_EXPORTER. "stdout" is accepted as an alias for console.
+const (
+	ExporterOTLP    = "otlp"
+	ExporterConsole = "console"
+	ExporterNone    = "none"
+)
+
+// OTLP protocols, matching OTEL_EXPORTER_OTLP_PROTOCOL.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+// OTLPConfig configures the OTLP exporters for all signals. Empty fields fall back to the
+// standard OTEL_EXPORTER_OTLP_* variables, which the exporters read
+// themselves, so either style of configuration works.
+type OTLPConfig struct {
+	// Protocol is grpc or http/protobuf, default grpc.
+	Protocol string
+	// Endpoint is host:port, or a URL whose scheme selects TLS.
+	Endpoint string
+	// Headers are sent with every export, e.g. a vendor API key.
+	Headers map[string]string
+	// CAFile is a PEM bundle used to verify the collector.
+	CAFile string
+	// Compression is gzip or none.
+	Compression string
+	// Insecure disables TLS.
+	Insecure bool
+}
+
+// signal is the per-signal suffix of the OTEL_EXPORTER_OTLP_* variables.
+type signal string
+
+const (
+	signalTraces  signal = "TRACES"
+	signalMetrics signal = "METRICS"
+	signalLogs    signal = "LOGS"
+)
+
+// env returns the signal specific variable, e.g.
+// OTEL_EXPORTER_OTLP_TRACES_PROTOCOL, or else the general one.
+func (s signal) env(name string) string {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_" + string(s) + "_" + name); v != "" {
+		return v
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + name)
+}
+
+func (c OTLPConfig) protocol(s signal) (string, error) {
+	protocol := c.Protocol
+	if protocol == "" {
+		protocol = s.env("PROTOCOL")
+	}
+	switch protocol {
+	case "", ProtocolGRPC:
+		return ProtocolGRPC, nil
+	case ProtocolHTTP, "http":
+		return ProtocolHTTP, nil
+	default:
+		return "", fmt.Errorf("unsupported otlp protocol %q", protocol)
+	}
+}
+
+// hasEndpoint reports whether an endpoint is configured for the signal.
+func (c OTLPConfig) hasEndpoint(s signal) bool {
+	return strings.TrimSpace(c.Endpoint) != "" || s.env("ENDPOINT") != ""
+}
+
+func (c OTLPConfig) tlsConfig() (*tls.Config, error) {
+	if c.CAFile == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading otlp ca file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// exporterName resolves the configured exporter, falling back to the given
+// OTEL_*_EXPORTER variable and then def.
+func exporterName(configured, envName, def string) (string, error) {
+	name := configured
+	if name == "" {
+		name = os.Getenv(envName)
+	}
+	if name == "" {
+		return def, nil
+	}
+	switch name {
+	case ExporterOTLP, ExporterNone:
+		return name, nil
+	case ExporterConsole, "stdout":
+		return ExporterConsole, nil
+	// Prometheus is served by MetricsHandler rather than pushed.
+	case "prometheus":
+		return ExporterNone, nil
+	default:
+		return "", fmt.Errorf("unsupported exporter %q", name)
+	}
+}
+
+// otlpOptions holds one exporter package's option constructors, so the
+// shared settings are translated the same way for every signal and protocol.
+type otlpOptions[O any] struct {
+	endpointURL func(string) O
+	endpoint    func(string) O
+	headers     func(map[string]string) O
+	tls         func(*tls.Config) O
+	// compression maps the supported Compression values to their option.
+	compression map[string]O
+	insecure    func() O
+}
+
+// build returns the options for the settings in c that are set.
+func (o otlpOptions[O]) build(c OTLPConfig, tlsConfig *tls.Config) []O {
+	var opts []O
+	endpoint := strings.TrimSpace(c.Endpoint)
+	switch {
+	case strings.Contains(endpoint, "://"):
+		opts = append(opts, o.endpointURL(endpoint))
+	case endpoint != "":
+		opts = append(opts, o.endpoint(endpoint))
+	}
+	if len(c.Headers) > 0 {
+		opts = append(opts, o.headers(c.Headers))
+	}
+	if tlsConfig != nil {
+		opts = append(opts, o.tls(tlsConfig))
+	}
+	if opt, ok := o.compression[c.Compression]; ok {
+		opts = append(opts, opt)
+	}
+	if c.Insecure {
+		opts = append(opts, o.insecure())
+	}
+	return opts
+}
+
+// grpcTLS adapts a TLS config to the grpc exporters' credentials option.
+func grpcTLS[O any](withCredentials func(credentials.TransportCredentials) O) func(*tls.Config) O {
+	return func(c *tls.Config) O { return withCredentials(credentials.NewTLS(c)) }
+}
+
+// transport returns the protocol and TLS config for the signal's exporter.
+func (c OTLPConfig) transport(s signal) (string, *tls.Config, error) {
+	protocol, err := c.protocol(s)
+	if err != nil {
+		return "", nil, err
+	}
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	return protocol, tlsConfig, nil
+}
+
+// newSpanExporter returns nil when traces should not be exported.
+func newSpanExporter(ctx context.Context, cfg Config) (trace.SpanExporter, error) {
+	name, err := exporterName(cfg.TracingExporter, "OTEL_TRACES_EXPORTER", ExporterOTLP)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case ExporterConsole:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		if !cfg.OTLP.hasEndpoint(signalTraces) {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	protocol, tlsConfig, err := cfg.OTLP.transport(signalTraces)
+	if err != nil {
+		return nil, err
+	}
+	if protocol == ProtocolHTTP {
+		return otlptracehttp.New(ctx, otlpOptions[otlptracehttp.Option]{
+			endpointURL: otlptracehttp.WithEndpointURL,
+			endpoint:    otlptracehttp.WithEndpoint,
+			headers:     otlptracehttp.WithHeaders,
+			tls:         otlptracehttp.WithTLSClientConfig,
+			compression: map[string]otlptracehttp.Option{
+				"gzip": otlptracehttp.WithCompression(otlptracehttp.GzipCompression),
+				"none": otlptracehttp.WithCompression(otlptracehttp.NoCompression),
+			},
+			insecure: otlptracehttp.WithInsecure,
+		}.build(cfg.OTLP, tlsConfig)...)
+	}
+	return otlptracegrpc.New(ctx, otlpOptions[otlptracegrpc.Option]{
+		endpointURL: otlptracegrpc.WithEndpointURL,
+		endpoint:    otlptracegrpc.WithEndpoint,
+		headers:     otlptracegrpc.WithHeaders,
+		tls:         grpcTLS(otlptracegrpc.WithTLSCredentials),
+		compression: map[string]otlptracegrpc.Option{"gzip": otlptracegrpc.WithCompressor("gzip")},
+		insecure:    otlptracegrpc.WithInsecure,
+	}.build(cfg.OTLP, tlsConfig)...)
+}
+
+// newMetricExporter returns nil when metrics should not be pushed.
+func newMetricExporter(ctx context.Context, cfg Config) (metric.Exporter, error) {
+	name, err := exporterName(cfg.MetricsExporter, "OTEL_METRICS_EXPORTER", ExporterNone)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case ExporterConsole:
+		return stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+	case ExporterOTLP:
+		if !cfg.OTLP.hasEndpoint(signalMetrics) {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	protocol, tlsConfig, err := cfg.OTLP.transport(signalMetrics)
+	if err != nil {
+		return nil, err
+	}
+	if protocol == ProtocolHTTP {
+		return otlpmetrichttp.New(ctx, otlpOptions[otlpmetrichttp.Option]{
+			endpointURL: otlpmetrichttp.WithEndpointURL,
+			endpoint:    otlpmetrichttp.WithEndpoint,
+			headers:     otlpmetrichttp.WithHeaders,
+			tls:         otlpmetrichttp.WithTLSClientConfig,
+			compression: map[string]otlpmetrichttp.Option{
+				"gzip": otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression),
+				"none": otlpmetrichttp.WithCompression(otlpmetrichttp.NoCompression),
+			},
+			insecure: otlpmetrichttp.WithInsecure,
+		}.build(cfg.OTLP, tlsConfig)...)
+	}
+	return otlpmetricgrpc.New(ctx, otlpOptions[otlpmetricgrpc.Option]{
+		endpointURL: otlpmetricgrpc.WithEndpointURL,
+		endpoint:    otlpmetricgrpc.WithEndpoint,
+		headers:     otlpmetricgrpc.WithHeaders,
+		tls:         grpcTLS(otlpmetricgrpc.WithTLSCredentials),
+		compression: map[string]otlpmetricgrpc.Option{"gzip": otlpmetricgrpc.WithCompressor("gzip")},
+		insecure:    otlpmetricgrpc.WithInsecure,
+	}.build(cfg.OTLP, tlsConfig)...)
+}
+
+// newLogExporter returns nil when logs should not be exported.
+func newLogExporter(ctx context.Context, cfg Config) (sdklog.Exporter, error) {
+	name, err := exporterName(cfg.LogsExporter, "OTEL_LOGS_EXPORTER", ExporterNone)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case ExporterConsole:
+		return stdoutlog.New(stdoutlog.WithPrettyPrint())
+	case ExporterOTLP:
+		if !cfg.OTLP.hasEndpoint(signalLogs) {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	protocol, tlsConfig, err := cfg.OTLP.transport(signalLogs)
+	if err != nil {
+		return nil, err
+	}
+	if protocol == ProtocolHTTP {
+		return otlploghttp.New(ctx, otlpOptions[otlploghttp.Option]{
+			endpointURL: otlploghttp.WithEndpointURL,
+			endpoint:    otlploghttp.WithEndpoint,
+			headers:     otlploghttp.WithHeaders,
+			tls:         otlploghttp.WithTLSClientConfig,
+			compression: map[string]otlploghttp.Option{
+				"gzip": otlploghttp.WithCompression(otlploghttp.GzipCompression),
+				"none": otlploghttp.WithCompression(otlploghttp.NoCompression),
+			},
+			insecure: otlploghttp.WithInsecure,
+		}.build(cfg.OTLP, tlsConfig)...)
+	}
+	return otlploggrpc.New(ctx, otlpOptions[otlploggrpc.Option]{
+		endpointURL: otlploggrpc.WithEndpointURL,
+		endpoint:    otlploggrpc.WithEndpoint,
+		headers:     otlploggrpc.WithHeaders,
+		tls:         grpcTLS(otlploggrpc.WithTLSCredentials),
+		compression: map[string]otlploggrpc.Option{"gzip": otlploggrpc.WithCompressor("gzip")},
+		insecure:    otlploggrpc.WithInsecure,
+	}.build(cfg.OTLP, tlsConfig)...)
+}
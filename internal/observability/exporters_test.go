@@ -0,0 +1,80 @@
+package observability
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporterName(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "stdout")
+
+	name, err := exporterName("", "OTEL_TRACES_EXPORTER", ExporterOTLP)
+	require.NoError(t, err)
+	require.Equal(t, ExporterConsole, name)
+
+	name, err = exporterName("none", "OTEL_TRACES_EXPORTER", ExporterOTLP)
+	require.NoError(t, err)
+	require.Equal(t, ExporterNone, name)
+
+	name, err = exporterName("", "OTEL_METRICS_EXPORTER", ExporterNone)
+	require.NoError(t, err)
+	require.Equal(t, ExporterNone, name)
+
+	_, err = exporterName("zipkin", "OTEL_TRACES_EXPORTER", ExporterOTLP)
+	require.Error(t, err)
+}
+
+func TestOTLPProtocolFallsBackToEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+
+	protocol, err := OTLPConfig{}.protocol(signalTraces)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolGRPC, protocol)
+
+	protocol, err = OTLPConfig{}.protocol(signalMetrics)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolHTTP, protocol)
+
+	protocol, err = OTLPConfig{Protocol: "http"}.protocol(signalTraces)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolHTTP, protocol)
+}
+
+func TestNewSpanExporterWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	exporter, err := newSpanExporter(t.Context(), Config{})
+	require.NoError(t, err)
+	require.Nil(t, exporter)
+
+	exporter, err = newSpanExporter(t.Context(), Config{TracingExporter: ExporterConsole})
+	require.NoError(t, err)
+	require.NotNil(t, exporter)
+}
+
+func TestOTLPOptionsBuild(t *testing.T) {
+	options := otlpOptions[string]{
+		endpointURL: func(u string) string { return "url=" + u },
+		endpoint:    func(e string) string { return "endpoint=" + e },
+		headers:     func(map[string]string) string { return "headers" },
+		tls:         func(*tls.Config) string { return "tls" },
+		compression: map[string]string{"gzip": "gzip"},
+		insecure:    func() string { return "insecure" },
+	}
+
+	require.Empty(t, options.build(OTLPConfig{}, nil))
+	require.Equal(t, []string{"endpoint=collector:4317", "headers", "tls", "gzip"}, options.build(OTLPConfig{
+		Endpoint:    " collector:4317 ",
+		Headers:     map[string]string{"api-key": "secret"},
+		Compression: "gzip",
+	}, &tls.Config{}))
+	require.Equal(t, []string{"url=http://collector:4318", "insecure"}, options.build(OTLPConfig{
+		Endpoint:    "http://collector:4318",
+		Compression: "none",
+		Insecure:    true,
+	}, nil))
+}
@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// createLoggerProvider returns nil when logs are not exported.
+func createLoggerProvider(cfg Config, res *resource.Resource) (*sdklog.LoggerProvider, error) {
+	exporter, err := newLogExporter(context.Background(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log exporter: %w", err)
+	}
+	if exporter == nil {
+		return nil, nil
+	}
+
+	return sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	), nil
+}
+
+// newLogHandler bridges slog records at or above level to provider.
+func newLogHandler(cfg Config, provider *sdklog.LoggerProvider) slog.Handler {
+	return &levelHandler{
+		Handler: otelslog.NewHandler(cfg.ServiceName,
+			otelslog.WithLoggerProvider(provider),
+			otelslog.WithVersion(cfg.ServiceVersion),
+		),
+		level: cfg.LogsLevel,
+	}
+}
+
+// levelHandler drops records below level before they reach Handler.
+type levelHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.Handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
@@ -0,0 +1,36 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+type recordingExporter struct {
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error   { return nil }
+func (e *recordingExporter) ForceFlush(context.Context) error { return nil }
+
+func TestLogHandlerFiltersByLevel(t *testing.T) {
+	exporter := &recordingExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	logger := slog.New(newLogHandler(Config{ServiceName: "test", LogsLevel: slog.LevelWarn}, provider))
+
+	logger.Info("dropped")
+	logger.Warn("exported", "filename", "report.csv")
+
+	require.Len(t, exporter.records, 1)
+	require.Equal(t, "exported", exporter.records[0].Body().AsString())
+}
@@ -5,11 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
+	"net/http"
+	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
@@ -22,16 +28,70 @@ type Config struct {
 	ServiceVersion string
 	Environment    string
 
-	Logger            *slog.Logger
-	TracingEnabled    bool
-	TracingEndpoint   string
+	Logger         *slog.Logger
+	TracingEnabled bool
+	// TracingExporter is otlp, console or none. Empty reads
+	// OTEL_TRACES_EXPORTER and defaults to otlp.
+	TracingExporter   string
 	TracingSampleRate float64
+
+	// MetricsPrometheus serves metrics for scraping through MetricsHandler.
+	MetricsPrometheus bool
+	// MetricsExporter pushes metrics every MetricsInterval: otlp, console or
+	// none. Empty reads OTEL_METRICS_EXPORTER and defaults to none.
+	MetricsExporter string
+	MetricsInterval time.Duration
+
+	// LogsExporter sends records from LogHandler to otlp, console or none.
+	// Empty reads OTEL_LOGS_EXPORTER and defaults to none.
+	LogsExporter string
+	// LogsLevel is the lowest level exported; the zero value is info.
+	LogsLevel slog.Leveler
+
+	// OTLP configures the otlp trace, metric and log exporters.
+	OTLP OTLPConfig
 }
 
 // Telemetry contains providers used by the API server/client.
 type Telemetry struct {
 	MeterProvider  *metric.MeterProvider
 	TracerProvider *trace.TracerProvider
+	// MetricsHandler serves the Prometheus exposition format. It is nil
+	// unless MetricsPrometheus is set.
+	MetricsHandler http.Handler
+	// LoggerProvider and LogHandler are nil unless logs are exported.
+	// Records handled by LogHandler go to LoggerProvider.
+	LoggerProvider *sdklog.LoggerProvider
+	LogHandler     slog.Handler
+
+	tracingErr error
+	errors     *errorRecorder
+}
+
+// exportErrorWindow is how long an export error is reported by TracingHealth.
+const exportErrorWindow = 5 * time.Minute
+
+// errorRecorder keeps the last error returned by the span exporter.
+type errorRecorder struct {
+	mu   sync.Mutex
+	err  error
+	when time.Time
+}
+
+func (r *errorRecorder) Handle(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+	r.when = time.Now()
+}
+
+func (r *errorRecorder) recent() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil && time.Since(r.when) < exportErrorWindow {
+		return r.err
+	}
+	return nil
 }
 
 func New(cfg Config) (*Telemetry, error) {
@@ -40,16 +100,41 @@ func New(cfg Config) (*Telemetry, error) {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	meterProvider := metric.NewMeterProvider(
-		metric.WithResource(res),
-	)
+	readers, metricsHandler, err := createMetricReaders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	meterOpts := []metric.Option{metric.WithResource(res)}
+	for _, reader := range readers {
+		meterOpts = append(meterOpts, metric.WithReader(reader))
+	}
+	meterProvider := metric.NewMeterProvider(meterOpts...)
 	otel.SetMeterProvider(meterProvider)
 
-	tracerProvider, err := createTracerProvider(cfg, res)
+	recorder := &errorRecorder{}
+	tracerProvider, tracingErr := createTracerProvider(cfg, res, recorder)
+	otel.SetTracerProvider(tracerProvider)
+
+	if cfg.LogsLevel == nil {
+		cfg.LogsLevel = slog.LevelInfo
+	}
+	loggerProvider, err := createLoggerProvider(cfg, res)
 	if err != nil {
 		return nil, err
 	}
-	otel.SetTracerProvider(tracerProvider)
+	var logHandler slog.Handler
+	if loggerProvider != nil {
+		logHandler = newLogHandler(cfg, loggerProvider)
+	}
+
+	// Errors from every signal end up here, so they are only logged; span
+	// export failures reach TracingHealth through the exporter itself.
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		if cfg.Logger != nil {
+			cfg.Logger.Warn("opentelemetry error", "error", err)
+		}
+	}))
 
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
 		propagation.TraceContext{},
@@ -59,12 +144,31 @@ func New(cfg Config) (*Telemetry, error) {
 	return &Telemetry{
 		MeterProvider:  meterProvider,
 		TracerProvider: tracerProvider,
+		MetricsHandler: metricsHandler,
+		LoggerProvider: loggerProvider,
+		LogHandler:     logHandler,
+		tracingErr:     tracingErr,
+		errors:         recorder,
 	}, nil
 }
 
+// TracingHealth returns an error if the trace exporter could not be created
+// or failed to export spans in the last five minutes. It returns nil when tracing is disabled.
+func (t *Telemetry) TracingHealth(ctx context.Context) error {
+	if t.tracingErr != nil {
+		return t.tracingErr
+	}
+	if t.errors != nil {
+		if err := t.errors.recent(); err != nil {
+			return fmt.Errorf("tracing: %w", err)
+		}
+	}
+	return nil
+}
+
 func createResource(cfg Config) (*resource.Resource, error) {
 	// Avoid schema conflicts when merging with auto-detected default resources.
-	return resource.Merge(
+	res, err := resource.Merge(
 		resource.Default(),
 		resource.NewWithAttributes(
 			"",
@@ -73,34 +177,68 @@ func createResource(cfg Config) (*resource.Resource, error) {
 			semconv.DeploymentEnvironment(cfg.Environment),
 		),
 	)
+	if err != nil {
+		return nil, err
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence.
+	return resource.Merge(res, resource.Environment())
 }
 
-func createTracerProvider(cfg Config, res *resource.Resource) (*trace.TracerProvider, error) {
-	if !cfg.TracingEnabled {
-		return newNoopTracerProvider(res), nil
-	}
+// createMetricReaders returns the readers for the enabled metric exporters
+// and, for Prometheus, the handler serving its registry.
+func createMetricReaders(cfg Config) ([]metric.Reader, http.Handler, error) {
+	var readers []metric.Reader
+	var handler http.Handler
 
-	endpoint := strings.TrimSpace(cfg.TracingEndpoint)
-	if endpoint == "" {
-		return newNoopTracerProvider(res), nil
+	if cfg.MetricsPrometheus {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+
+		exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+		}
+		readers = append(readers, exporter)
+		handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
 	}
 
-	opts := []otlptracegrpc.Option{
-		otlptracegrpc.WithEndpoint(endpoint),
+	exporter, err := newMetricExporter(context.Background(), cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create metric exporter: %w", err)
+	}
+	if exporter != nil {
+		var readerOpts []metric.PeriodicReaderOption
+		if cfg.MetricsInterval > 0 {
+			readerOpts = append(readerOpts, metric.WithInterval(cfg.MetricsInterval))
+		}
+		readers = append(readers, metric.NewPeriodicReader(exporter, readerOpts...))
 	}
-	if cfg.Environment != "production" {
-		opts = append(opts, otlptracegrpc.WithInsecure())
+
+	return readers, handler, nil
+}
+
+// createTracerProvider falls back to a noop provider if the exporter cannot be
+// created, returning the reason alongside it. Export errors go to recorder.
+func createTracerProvider(cfg Config, res *resource.Resource, recorder *errorRecorder) (*trace.TracerProvider, error) {
+	if !cfg.TracingEnabled {
+		return newNoopTracerProvider(res), nil
 	}
 
-	exporter, err := otlptracegrpc.New(context.Background(), opts...)
+	exporter, err := newSpanExporter(context.Background(), cfg)
 	if err != nil {
 		if cfg.Logger != nil {
 			cfg.Logger.Warn(
 				"tracing exporter initialization failed; falling back to noop tracer",
-				"endpoint", endpoint,
+				"endpoint", cfg.OTLP.Endpoint,
 				"error", err,
 			)
 		}
+		return newNoopTracerProvider(res), fmt.Errorf("tracing exporter initialization failed: %w", err)
+	}
+	if exporter == nil {
 		return newNoopTracerProvider(res), nil
 	}
 
@@ -113,12 +251,26 @@ func createTracerProvider(cfg Config, res *resource.Resource) (*trace.TracerProv
 	}
 
 	return trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
+		trace.WithBatcher(&recordingSpanExporter{SpanExporter: exporter, errors: recorder}),
 		trace.WithResource(res),
 		trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(sampleRate))),
 	), nil
 }
 
+// recordingSpanExporter records failed exports for TracingHealth.
+type recordingSpanExporter struct {
+	trace.SpanExporter
+	errors *errorRecorder
+}
+
+func (e *recordingSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err != nil {
+		e.errors.Handle(err)
+	}
+	return err
+}
+
 func newNoopTracerProvider(res *resource.Resource) *trace.TracerProvider {
 	return trace.NewTracerProvider(
 		trace.WithResource(res),
@@ -134,5 +286,8 @@ func (t *Telemetry) Shutdown(ctx context.Context) error {
 	if t.MeterProvider != nil {
 		shutdownErr = errors.Join(shutdownErr, t.MeterProvider.Shutdown(ctx))
 	}
+	if t.LoggerProvider != nil {
+		shutdownErr = errors.Join(shutdownErr, t.LoggerProvider.Shutdown(ctx))
+	}
 	return shutdownErr
 }
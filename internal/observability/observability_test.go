@@ -0,0 +1,33 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+type failingSpanExporter struct{}
+
+func (failingSpanExporter) ExportSpans(context.Context, []trace.ReadOnlySpan) error {
+	return errors.New("collector unavailable")
+}
+
+func (failingSpanExporter) Shutdown(context.Context) error { return nil }
+
+func TestTracingHealthOnlyReportsSpanExportErrors(t *testing.T) {
+	telemetry, err := New(Config{ServiceName: "test"})
+	require.NoError(t, err)
+	defer telemetry.Shutdown(context.Background())
+
+	// Errors from other signals reach the global handler but not the check.
+	otel.Handle(errors.New("failed to upload metrics"))
+	require.NoError(t, telemetry.TracingHealth(context.Background()))
+
+	exporter := &recordingSpanExporter{SpanExporter: failingSpanExporter{}, errors: telemetry.errors}
+	require.Error(t, exporter.ExportSpans(context.Background(), nil))
+	require.ErrorContains(t, telemetry.TracingHealth(context.Background()), "collector unavailable")
+}
@@ -0,0 +1,382 @@
+package pii
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Kind is a category of sensitive data the scanner can detect.
+type Kind string
+
+const (
+	KindPAN   Kind = "pan"
+	KindIBAN  Kind = "iban"
+	KindEmail Kind = "email"
+)
+
+// AllKinds lists every supported kind in detection order.
+var AllKinds = []Kind{KindEmail, KindIBAN, KindPAN}
+
+// Action is what happens to cells containing sensitive data.
+type Action string
+
+const (
+	ActionOff      Action = ""
+	ActionReject   Action = "reject"
+	ActionMask     Action = "mask"
+	ActionTokenise Action = "tokenise"
+)
+
+var (
+	ErrSensitiveData = errors.New("sensitive data detected")
+	ErrInvalidConfig = errors.New("invalid pii config")
+)
+
+var (
+	// panPattern finds digit runs; card numbers are searched for inside them
+	// since a run may hold more digits than the card, e.g. "4111 1111 1111 1111 12/27".
+	panPattern   = regexp.MustCompile(`\b(?:\d[ -]?){12,}\d\b`)
+	ibanPattern  = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// Config is the per-deployment scanning policy.
+type Config struct {
+	Action   Action
+	Kinds    []Kind
+	TokenKey []byte
+}
+
+// Column summarises the sensitive data found in a single CSV column.
+type Column struct {
+	Index int
+	Name  string
+	Kinds []Kind
+	Cells int
+}
+
+// RejectedError is returned when the policy is ActionReject and sensitive data was found.
+type RejectedError struct {
+	Columns []Column
+}
+
+func (e *RejectedError) Error() string {
+	names := make([]string, 0, len(e.Columns))
+	for _, c := range e.Columns {
+		names = append(names, c.Name)
+	}
+	return fmt.Sprintf("%s in columns: %s", ErrSensitiveData, strings.Join(names, ", "))
+}
+
+func (e *RejectedError) Is(target error) bool {
+	return target == ErrSensitiveData
+}
+
+// Scanner detects and redacts sensitive data. A nil Scanner is a no-op.
+type Scanner struct {
+	action   Action
+	kinds    []Kind
+	tokenKey []byte
+}
+
+// New validates the config and builds a scanner.
+func New(cfg Config) (*Scanner, error) {
+	action := Action(strings.ToLower(strings.TrimSpace(string(cfg.Action))))
+	switch action {
+	case ActionOff, ActionReject, ActionMask:
+	case ActionTokenise:
+		if len(cfg.TokenKey) == 0 {
+			return nil, fmt.Errorf("%w: tokenise requires a token key", ErrInvalidConfig)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidConfig, cfg.Action)
+	}
+
+	kinds := cfg.Kinds
+	if len(kinds) == 0 {
+		kinds = AllKinds
+	}
+
+	// Keep detection order stable so overlapping matches resolve consistently.
+	enabled := make([]Kind, 0, len(AllKinds))
+	for _, kind := range AllKinds {
+		if slices.Contains(kinds, kind) {
+			enabled = append(enabled, kind)
+		}
+	}
+	for _, kind := range kinds {
+		if !slices.Contains(AllKinds, kind) {
+			return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidConfig, kind)
+		}
+	}
+
+	return &Scanner{
+		action:   action,
+		kinds:    enabled,
+		tokenKey: cfg.TokenKey,
+	}, nil
+}
+
+// Action returns the configured action.
+func (s *Scanner) Action() Action {
+	if s == nil {
+		return ActionOff
+	}
+	return s.action
+}
+
+// ScanCSV scans every cell of a CSV payload and applies the policy. The payload
+// is only re-encoded when a cell was changed.
+func (s *Scanner) ScanCSV(payload []byte) ([]byte, []Column, error) {
+	if s.Action() == ActionOff {
+		return payload, nil, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(payload))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var (
+		records [][]string
+		header  []string
+		columns = map[int]*Column{}
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing csv: %w", err)
+		}
+
+		if header == nil {
+			header = slices.Clone(record)
+		}
+
+		for i, cell := range record {
+			redacted, kinds := s.scanCell(cell)
+			if len(kinds) == 0 {
+				continue
+			}
+
+			col, ok := columns[i]
+			if !ok {
+				col = &Column{Index: i}
+				columns[i] = col
+			}
+			col.Cells++
+			for _, kind := range kinds {
+				if !slices.Contains(col.Kinds, kind) {
+					col.Kinds = append(col.Kinds, kind)
+				}
+			}
+			record[i] = redacted
+		}
+
+		records = append(records, record)
+	}
+
+	if len(columns) == 0 {
+		return payload, nil, nil
+	}
+
+	found := make([]Column, 0, len(columns))
+	for i, col := range columns {
+		col.Name = s.columnName(header, i)
+		found = append(found, *col)
+	}
+	slices.SortFunc(found, func(a, b Column) int { return a.Index - b.Index })
+
+	if s.action == ActionReject {
+		return nil, found, &RejectedError{Columns: found}
+	}
+
+	out := &bytes.Buffer{}
+	writer := csv.NewWriter(out)
+	writer.UseCRLF = bytes.Contains(payload, []byte("\r\n"))
+	if err := writer.WriteAll(records); err != nil {
+		return nil, nil, fmt.Errorf("writing csv: %w", err)
+	}
+
+	return out.Bytes(), found, nil
+}
+
+// columnName uses the header cell unless the header row itself held sensitive
+// data, which means the file has no header.
+func (s *Scanner) columnName(header []string, index int) string {
+	if index < len(header) && header[index] != "" {
+		if _, kinds := s.scanCell(header[index]); len(kinds) == 0 {
+			return header[index]
+		}
+	}
+	return fmt.Sprintf("column_%d", index+1)
+}
+
+func (s *Scanner) scanCell(cell string) (string, []Kind) {
+	var kinds []Kind
+	for _, kind := range s.kinds {
+		pattern, find := detector(kind)
+		cell = pattern.ReplaceAllStringFunc(cell, func(match string) string {
+			spans := find(match)
+			if len(spans) == 0 {
+				return match
+			}
+			if !slices.Contains(kinds, kind) {
+				kinds = append(kinds, kind)
+			}
+			var out strings.Builder
+			end := 0
+			for _, span := range spans {
+				out.WriteString(match[end:span[0]])
+				out.WriteString(s.redact(match[span[0]:span[1]]))
+				end = span[1]
+			}
+			out.WriteString(match[end:])
+			return out.String()
+		})
+	}
+	return cell, kinds
+}
+
+// detector returns the pattern for a kind and a function returning the
+// sensitive spans within a match.
+func detector(kind Kind) (*regexp.Regexp, func(string) [][2]int) {
+	whole := func(valid func(string) bool) func(string) [][2]int {
+		return func(m string) [][2]int {
+			if !valid(m) {
+				return nil
+			}
+			return [][2]int{{0, len(m)}}
+		}
+	}
+	switch kind {
+	case KindPAN:
+		return panPattern, panSpans
+	case KindIBAN:
+		return ibanPattern, whole(func(m string) bool { return ibanValid(stripSeparators(m)) })
+	default:
+		return emailPattern, whole(func(string) bool { return true })
+	}
+}
+
+// panSpans returns the Luhn-valid windows of 13 to 19 digits in a digit run,
+// preferring the longest window at each position.
+func panSpans(run string) [][2]int {
+	var positions []int
+	for i := 0; i < len(run); i++ {
+		if run[i] >= '0' && run[i] <= '9' {
+			positions = append(positions, i)
+		}
+	}
+
+	var spans [][2]int
+	digits := make([]byte, 0, 19)
+	for start := 0; start+13 <= len(positions); {
+		found := 0
+		for n := min(19, len(positions)-start); n >= 13; n-- {
+			digits = digits[:0]
+			for _, pos := range positions[start : start+n] {
+				digits = append(digits, run[pos])
+			}
+			if luhnValid(string(digits)) {
+				found = n
+				break
+			}
+		}
+		if found == 0 {
+			start++
+			continue
+		}
+		spans = append(spans, [2]int{positions[start], positions[start+found-1] + 1})
+		start += found
+	}
+	return spans
+}
+
+func (s *Scanner) redact(match string) string {
+	switch s.action {
+	case ActionMask:
+		return mask(match)
+	case ActionTokenise:
+		return s.tokenise(match)
+	default:
+		return match
+	}
+}
+
+// mask keeps the last four characters of the value.
+func mask(value string) string {
+	normalised := stripSeparators(value)
+	if len(normalised) <= 4 {
+		return strings.Repeat("*", len(normalised))
+	}
+	return strings.Repeat("*", len(normalised)-4) + normalised[len(normalised)-4:]
+}
+
+// tokenise replaces the value with a deterministic keyed token so that
+// downstream joins on the column still work.
+func (s *Scanner) tokenise(value string) string {
+	mac := hmac.New(sha256.New, s.tokenKey)
+	mac.Write([]byte(strings.ToLower(stripSeparators(value))))
+	return "tok_" + hex.EncodeToString(mac.Sum(nil)[:12])
+}
+
+func stripSeparators(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(value)
+}
+
+func luhnValid(digits string) bool {
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ibanValid applies the ISO 13616 mod-97 check.
+func ibanValid(iban string) bool {
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
@@ -0,0 +1,127 @@
+package pii
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sample = "name,card,iban,contact\n" +
+	"Alice,4111 1111 1111 1111,GB82 WEST 1234 5698 7654 32,alice@example.com\n" +
+	"Bob,1234567890123,n/a,none\n"
+
+func TestLuhnValid(t *testing.T) {
+	require.True(t, luhnValid("4111111111111111"))
+	require.True(t, luhnValid("5500005555555559"))
+	require.False(t, luhnValid("4111111111111112"))
+	require.False(t, luhnValid("411111"))
+}
+
+func TestIBANValid(t *testing.T) {
+	require.True(t, ibanValid("GB82WEST12345698765432"))
+	require.True(t, ibanValid("DE89370400440532013000"))
+	require.False(t, ibanValid("GB82WEST12345698765433"))
+}
+
+func TestScanCSVMasksKeepingLastFour(t *testing.T) {
+	scanner, err := New(Config{Action: ActionMask})
+	require.NoError(t, err)
+
+	out, columns, err := scanner.ScanCSV([]byte(sample))
+	require.NoError(t, err)
+
+	require.Contains(t, string(out), "************1111")
+	require.Contains(t, string(out), "******************5432")
+	require.NotContains(t, string(out), "alice@example.com")
+	require.Contains(t, string(out), "1234567890123", "non-Luhn numbers are left alone")
+
+	require.Len(t, columns, 3)
+	require.Equal(t, Column{Index: 1, Name: "card", Kinds: []Kind{KindPAN}, Cells: 1}, columns[0])
+	require.Equal(t, "iban", columns[1].Name)
+	require.Equal(t, []Kind{KindEmail}, columns[2].Kinds)
+}
+
+func TestScanCSVFindsCardNumbersFollowedByDigits(t *testing.T) {
+	scanner, err := New(Config{Action: ActionMask})
+	require.NoError(t, err)
+
+	for _, cell := range []string{"4111 1111 1111 1111 12/27", "4111-1111-1111-1111 123"} {
+		out, columns, err := scanner.ScanCSV([]byte("card\n" + cell + "\n"))
+		require.NoError(t, err)
+		require.NotContains(t, string(out), "4111 1111", cell)
+		require.NotContains(t, string(out), "4111-1111", cell)
+		require.Contains(t, string(out), "************1111", cell)
+		require.Len(t, columns, 1, cell)
+		require.Equal(t, []Kind{KindPAN}, columns[0].Kinds)
+	}
+
+	out, _, err := scanner.ScanCSV([]byte("card\n4111 1111 1111 1111 12/27\n"))
+	require.NoError(t, err)
+	require.Equal(t, "card\n************1111 12/27\n", string(out))
+}
+
+func TestScanCSVTokenisesDeterministically(t *testing.T) {
+	scanner, err := New(Config{Action: ActionTokenise, TokenKey: []byte("secret"), Kinds: []Kind{KindPAN}})
+	require.NoError(t, err)
+
+	out, columns, err := scanner.ScanCSV([]byte("a,b\n4111111111111111,4111-1111-1111-1111\n"))
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	cells := strings.Split(lines[1], ",")
+	require.True(t, strings.HasPrefix(cells[0], "tok_"))
+	require.Equal(t, cells[0], cells[1])
+}
+
+func TestScanCSVRejectsSensitiveData(t *testing.T) {
+	scanner, err := New(Config{Action: ActionReject})
+	require.NoError(t, err)
+
+	_, _, err = scanner.ScanCSV([]byte(sample))
+	require.ErrorIs(t, err, ErrSensitiveData)
+
+	var rejected *RejectedError
+	require.ErrorAs(t, err, &rejected)
+	require.Len(t, rejected.Columns, 3)
+}
+
+func TestScanCSVNamesHeaderlessColumnsByPosition(t *testing.T) {
+	scanner, err := New(Config{Action: ActionMask, Kinds: []Kind{KindEmail}})
+	require.NoError(t, err)
+
+	_, columns, err := scanner.ScanCSV([]byte("1,bob@example.com\n"))
+	require.NoError(t, err)
+	require.Equal(t, "column_2", columns[0].Name)
+}
+
+func TestScanCSVLeavesCleanPayloadUntouched(t *testing.T) {
+	scanner, err := New(Config{Action: ActionMask})
+	require.NoError(t, err)
+
+	payload := []byte("name,age\r\n\"Alice\",30\r\n")
+	out, columns, err := scanner.ScanCSV(payload)
+	require.NoError(t, err)
+	require.Empty(t, columns)
+	require.Equal(t, payload, out)
+}
+
+func TestNilScannerIsNoop(t *testing.T) {
+	var scanner *Scanner
+	out, columns, err := scanner.ScanCSV([]byte(sample))
+	require.NoError(t, err)
+	require.Nil(t, columns)
+	require.Equal(t, sample, string(out))
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	_, err := New(Config{Action: ActionTokenise})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = New(Config{Action: "shred"})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = New(Config{Action: ActionMask, Kinds: []Kind{"ssn"}})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
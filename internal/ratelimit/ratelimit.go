@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrLimited = errors.New("rate limit exceeded")
+
+// LimitError is returned when a request exceeds the rate limit or a quota.
+type LimitError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s, retry in %s", ErrLimited, e.Reason, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimited
+}
+
+// Config sets the per-principal limits. A zero value disables that limit.
+type Config struct {
+	// RequestsPerSecond refills the token bucket; Burst is its size.
+	RequestsPerSecond float64
+	Burst             int
+	// DailyBytes and DailyFiles cap uploads per UTC day.
+	DailyBytes int64
+	DailyFiles int64
+}
+
+// Status is what remains for a principal after a request.
+type Status struct {
+	Limit          int
+	Remaining      int
+	BytesRemaining int64
+	FilesRemaining int64
+	// QuotaReset is when the daily quotas reset.
+	QuotaReset time.Time
+}
+
+// Limiter keeps a token bucket and daily usage per principal in memory.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	usage   map[string]*usage
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type usage struct {
+	day   time.Time
+	bytes int64
+	files int64
+}
+
+func New(cfg Config) *Limiter {
+	if cfg.RequestsPerSecond > 0 && cfg.Burst <= 0 {
+		cfg.Burst = max(1, int(cfg.RequestsPerSecond))
+	}
+	return &Limiter{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+		usage:   map[string]*usage{},
+	}
+}
+
+// RateLimited reports whether the request rate limit is enabled.
+func (l *Limiter) RateLimited() bool {
+	return l.cfg.RequestsPerSecond > 0
+}
+
+// QuotaLimited reports whether a daily quota is enabled.
+func (l *Limiter) QuotaLimited() bool {
+	return l.cfg.DailyBytes > 0 || l.cfg.DailyFiles > 0
+}
+
+// Allow takes a token from the principal's bucket.
+func (l *Limiter) Allow(principal string) (Status, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	status := l.quotaStatus(principal, now)
+	if !l.RateLimited() {
+		return status, nil
+	}
+
+	b, ok := l.buckets[principal]
+	if !ok {
+		b = &bucket{tokens: float64(l.cfg.Burst), updated: now}
+		l.buckets[principal] = b
+	}
+	b.tokens = min(float64(l.cfg.Burst), b.tokens+now.Sub(b.updated).Seconds()*l.cfg.RequestsPerSecond)
+	b.updated = now
+
+	status.Limit = l.cfg.Burst
+	if b.tokens < 1 {
+		status.Remaining = 0
+		wait := time.Duration((1 - b.tokens) / l.cfg.RequestsPerSecond * float64(time.Second))
+		return status, &LimitError{Reason: "too many requests", RetryAfter: wait}
+	}
+
+	b.tokens--
+	status.Remaining = int(b.tokens)
+	return status, nil
+}
+
+// Reserve counts an upload of size bytes against the principal's daily
+// quotas. Call Release if the upload does not complete. Only the quota fields
+// of the returned status are set.
+func (l *Limiter) Reserve(principal string, size int64) (Status, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	u := l.usageFor(principal, now)
+	reset := nextDay(now)
+
+	if l.cfg.DailyFiles > 0 && u.files+1 > l.cfg.DailyFiles {
+		return l.quotaStatus(principal, now), &LimitError{Reason: "daily file quota exhausted", RetryAfter: reset.Sub(now)}
+	}
+	if l.cfg.DailyBytes > 0 && u.bytes+size > l.cfg.DailyBytes {
+		return l.quotaStatus(principal, now), &LimitError{Reason: "daily byte quota exhausted", RetryAfter: reset.Sub(now)}
+	}
+
+	u.files++
+	u.bytes += size
+	return l.quotaStatus(principal, now), nil
+}
+
+// Release returns a reservation made by Reserve.
+func (l *Limiter) Release(principal string, size int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.usageFor(principal, l.now())
+	u.files = max(0, u.files-1)
+	u.bytes = max(0, u.bytes-size)
+}
+
+func (l *Limiter) quotaStatus(principal string, now time.Time) Status {
+	u := l.usageFor(principal, now)
+	status := Status{QuotaReset: nextDay(now)}
+	if l.cfg.DailyBytes > 0 {
+		status.BytesRemaining = max(0, l.cfg.DailyBytes-u.bytes)
+	}
+	if l.cfg.DailyFiles > 0 {
+		status.FilesRemaining = max(0, l.cfg.DailyFiles-u.files)
+	}
+	return status
+}
+
+func (l *Limiter) usageFor(principal string, now time.Time) *usage {
+	day := now.UTC().Truncate(24 * time.Hour)
+	u, ok := l.usage[principal]
+	if !ok || !u.day.Equal(day) {
+		u = &usage{day: day}
+		l.usage[principal] = u
+	}
+	return u
+}
+
+func nextDay(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	now := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+	l := New(cfg)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestAllowRefillsTokens(t *testing.T) {
+	l, now := newTestLimiter(Config{RequestsPerSecond: 1, Burst: 2})
+
+	status, err := l.Allow("a")
+	require.NoError(t, err)
+	require.Equal(t, 2, status.Limit)
+	require.Equal(t, 1, status.Remaining)
+
+	_, err = l.Allow("a")
+	require.NoError(t, err)
+
+	_, err = l.Allow("a")
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, time.Second, limitErr.RetryAfter)
+
+	// Other principals have their own bucket.
+	_, err = l.Allow("b")
+	require.NoError(t, err)
+
+	*now = now.Add(time.Second)
+	_, err = l.Allow("a")
+	require.NoError(t, err)
+}
+
+func TestAllowWithoutRateLimit(t *testing.T) {
+	l, _ := newTestLimiter(Config{})
+	for range 100 {
+		_, err := l.Allow("a")
+		require.NoError(t, err)
+	}
+}
+
+func TestReserveEnforcesDailyQuotas(t *testing.T) {
+	l, now := newTestLimiter(Config{DailyBytes: 100, DailyFiles: 2})
+
+	status, err := l.Reserve("a", 60)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), status.BytesRemaining)
+	require.Equal(t, int64(1), status.FilesRemaining)
+
+	_, err = l.Reserve("a", 50)
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, time.Hour, limitErr.RetryAfter)
+
+	_, err = l.Reserve("a", 40)
+	require.NoError(t, err)
+
+	_, err = l.Reserve("a", 1)
+	require.ErrorIs(t, err, ErrLimited)
+
+	// Quotas reset at UTC midnight.
+	*now = now.Add(time.Hour)
+	_, err = l.Reserve("a", 100)
+	require.NoError(t, err)
+}
+
+func TestReleaseReturnsReservation(t *testing.T) {
+	l, _ := newTestLimiter(Config{DailyFiles: 1})
+
+	_, err := l.Reserve("a", 10)
+	require.NoError(t, err)
+	l.Release("a", 10)
+
+	_, err = l.Reserve("a", 10)
+	require.NoError(t, err)
+}
@@ -0,0 +1,129 @@
+// Package spool keeps queued records as one JSON file each in a directory,
+// so queues survive restarts. It backs the webhook queue and the event
+// outbox.
+package spool
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidID = errors.New("invalid spool record id")
+
+const ext = ".json"
+
+// Dir is a directory of records keyed by id.
+type Dir struct {
+	path string
+}
+
+// Open creates the directory if needed.
+func Open(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, fmt.Errorf("creating spool %s: %w", path, err)
+	}
+	return &Dir{path: path}, nil
+}
+
+// Put writes v as the record id, replacing any previous version atomically.
+func (d *Dir) Put(id string, v any) error {
+	file, err := d.file(id)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", id, err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", id, err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("writing %s: %w", id, err)
+	}
+	return nil
+}
+
+// Remove deletes the record id; a missing record is not an error.
+func (d *Dir) Remove(id string) error {
+	file, err := d.file(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing %s: %w", id, err)
+	}
+	return nil
+}
+
+// Move writes v as the record id in to and then removes it from d.
+func (d *Dir) Move(to *Dir, id string, v any) error {
+	if err := to.Put(id, v); err != nil {
+		return err
+	}
+	return d.Remove(id)
+}
+
+func (d *Dir) file(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+	return filepath.Join(d.path, id+ext), nil
+}
+
+// Load decodes every record in d, ordered by id. Ids from NewID sort in the
+// order they were created.
+func Load[T any](d *Dir) ([]T, error) {
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading spool %s: %w", d.path, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ext) {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+
+	records := make([]T, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(d.path, name))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		var record T
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", name, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// NewID returns a unique id that starts with t, so ids sort by time.
+func NewID(t time.Time) string {
+	suffix := make([]byte, 6)
+	_, _ = rand.Read(suffix)
+	return t.UTC().Format("20060102T150405.000000000Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// Backoff returns the wait after the given number of consecutive failures:
+// base, doubling per failure, capped at max.
+func Backoff(failures int, base, max time.Duration) time.Duration {
+	wait := base
+	for i := 1; i < failures && wait < max; i++ {
+		wait *= 2
+	}
+	return min(wait, max)
+}
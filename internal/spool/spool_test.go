@@ -0,0 +1,70 @@
+package spool
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID    string `json:"id"`
+	Value int    `json:"value"`
+}
+
+func TestPutLoadRemove(t *testing.T) {
+	dir, err := Open(filepath.Join(t.TempDir(), "pending"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	first := record{ID: NewID(now), Value: 1}
+	second := record{ID: NewID(now.Add(time.Millisecond)), Value: 2}
+	require.NoError(t, dir.Put(second.ID, second))
+	require.NoError(t, dir.Put(first.ID, first))
+
+	records, err := Load[record](dir)
+	require.NoError(t, err)
+	require.Equal(t, []record{first, second}, records)
+
+	first.Value = 3
+	require.NoError(t, dir.Put(first.ID, first))
+	require.NoError(t, dir.Remove(second.ID))
+	require.NoError(t, dir.Remove(second.ID))
+
+	records, err = Load[record](dir)
+	require.NoError(t, err)
+	require.Equal(t, []record{first}, records)
+}
+
+func TestMove(t *testing.T) {
+	base := t.TempDir()
+	pending, err := Open(filepath.Join(base, "pending"))
+	require.NoError(t, err)
+	dead, err := Open(filepath.Join(base, "dead"))
+	require.NoError(t, err)
+
+	r := record{ID: "a", Value: 1}
+	require.NoError(t, pending.Put(r.ID, r))
+	require.NoError(t, pending.Move(dead, r.ID, r))
+
+	records, err := Load[record](pending)
+	require.NoError(t, err)
+	require.Empty(t, records)
+	records, err = Load[record](dead)
+	require.NoError(t, err)
+	require.Equal(t, []record{r}, records)
+}
+
+func TestRejectsPathIDs(t *testing.T) {
+	dir, err := Open(t.TempDir())
+	require.NoError(t, err)
+	require.ErrorIs(t, dir.Put("../escape", record{}), ErrInvalidID)
+	require.ErrorIs(t, dir.Remove(""), ErrInvalidID)
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	require.Equal(t, time.Second, Backoff(1, time.Second, 10*time.Second))
+	require.Equal(t, 8*time.Second, Backoff(4, time.Second, 10*time.Second))
+	require.Equal(t, 10*time.Second, Backoff(60, time.Second, 10*time.Second))
+}
@@ -0,0 +1,208 @@
+"use strict";
+
+// Mirrors the server's detectContentType rules so obvious mistakes are caught
+// before uploading. The server still validates every file.
+const ALLOWED_EXTENSIONS = [".csv", ".xlsx"];
+const ZIP_MAGIC = [0x50, 0x4b, 0x03, 0x04];
+const RECENT_KEY = "file-upload.recent";
+const RECENT_LIMIT = 20;
+
+let maxUploadBytes = 10 * 1024 * 1024;
+
+const $ = (id) => document.getElementById(id);
+
+function formatBytes(bytes) {
+  if (bytes < 1024) return `${bytes} B`;
+  if (bytes < 1024 * 1024) return `${(bytes / 1024).toFixed(1)} KB`;
+  return `${(bytes / 1024 / 1024).toFixed(1)} MB`;
+}
+
+async function checkFile(file) {
+  const dot = file.name.lastIndexOf(".");
+  const ext = dot >= 0 ? file.name.slice(dot).toLowerCase() : "";
+  if (!ALLOWED_EXTENSIONS.includes(ext)) {
+    return `unsupported extension "${ext}", only CSV and XLSX are accepted`;
+  }
+  if (file.size === 0) {
+    return "file is empty";
+  }
+  if (file.size > maxUploadBytes) {
+    return `file is ${formatBytes(file.size)}, the limit is ${formatBytes(maxUploadBytes)}`;
+  }
+
+  const head = new Uint8Array(await file.slice(0, 512).arrayBuffer());
+  const isZip = ZIP_MAGIC.every((b, i) => head[i] === b);
+  if (ext === ".xlsx" && !isZip) {
+    return "not a valid XLSX file";
+  }
+  if (ext === ".csv") {
+    const text = new TextDecoder().decode(head).trimStart();
+    if (isZip || text.startsWith("{") || text.startsWith("[")) {
+      return "not a valid CSV file";
+    }
+  }
+  return null;
+}
+
+function authHeader() {
+  if (document.querySelector("input[name=auth]:checked").value === "apikey") {
+    const key = $("apikey").value.trim();
+    return key ? ["X-API-Key", key] : null;
+  }
+  const username = $("username").value;
+  const password = $("password").value;
+  if (!username || !password) return null;
+  const encoded = btoa(String.fromCharCode(...new TextEncoder().encode(`${username}:${password}`)));
+  return ["Authorization", `Basic ${encoded}`];
+}
+
+function errorMessage(xhr) {
+  try {
+    const body = JSON.parse(xhr.responseText);
+    const details = (body.details || []).join("; ");
+    return details ? `${body.message}: ${details}` : body.message;
+  } catch {
+    return `HTTP ${xhr.status}`;
+  }
+}
+
+function queueItem(file) {
+  const li = document.createElement("li");
+  const name = document.createElement("div");
+  name.textContent = `${file.name} (${formatBytes(file.size)})`;
+  const progress = document.createElement("progress");
+  progress.max = 100;
+  progress.value = 0;
+  const status = document.createElement("div");
+  li.append(name, progress, status);
+  $("queue").prepend(li);
+
+  return {
+    progress: (percent) => { progress.value = percent; },
+    fail: (message) => {
+      progress.remove();
+      status.className = "error";
+      status.textContent = message;
+    },
+    done: (response) => {
+      progress.value = 100;
+      status.className = "done";
+      status.textContent = `Uploaded to ${response.gcspath}`;
+    },
+  };
+}
+
+function upload(file, item) {
+  const header = authHeader();
+  if (!header) {
+    item.fail("enter your credentials first");
+    return;
+  }
+
+  const form = new FormData();
+  form.append("file", file, file.name);
+  const prefix = $("prefix").value.trim();
+  if (prefix) form.append("prefix", prefix);
+
+  const xhr = new XMLHttpRequest();
+  xhr.open("POST", "../upload");
+  xhr.setRequestHeader(header[0], header[1]);
+  xhr.upload.onprogress = (e) => {
+    if (e.lengthComputable) item.progress(Math.round((e.loaded / e.total) * 100));
+  };
+  xhr.onerror = () => item.fail("network error");
+  xhr.onload = () => {
+    if (xhr.status !== 200) {
+      let message = errorMessage(xhr);
+      const retryAfter = xhr.getResponseHeader("Retry-After");
+      if (retryAfter) message += ` (retry in ${retryAfter}s)`;
+      item.fail(message);
+      return;
+    }
+    const response = JSON.parse(xhr.responseText);
+    item.done(response);
+    addRecent(response);
+  };
+  xhr.send(form);
+}
+
+async function handleFiles(files) {
+  for (const file of files) {
+    const item = queueItem(file);
+    const problem = await checkFile(file);
+    if (problem) {
+      item.fail(problem);
+      continue;
+    }
+    upload(file, item);
+  }
+}
+
+function loadRecent() {
+  try {
+    return JSON.parse(localStorage.getItem(RECENT_KEY)) || [];
+  } catch {
+    return [];
+  }
+}
+
+function addRecent(response) {
+  const recent = [response, ...loadRecent()].slice(0, RECENT_LIMIT);
+  localStorage.setItem(RECENT_KEY, JSON.stringify(recent));
+  renderRecent();
+}
+
+function renderRecent() {
+  const tbody = $("recent").querySelector("tbody");
+  tbody.replaceChildren();
+  for (const r of loadRecent()) {
+    const row = document.createElement("tr");
+    for (const value of [r.filename, formatBytes(r.fileSize), new Date(r.uploadTime).toLocaleString(), r.gcspath]) {
+      const cell = document.createElement("td");
+      cell.textContent = value;
+      row.append(cell);
+    }
+    tbody.append(row);
+  }
+}
+
+function init() {
+  for (const radio of document.querySelectorAll("input[name=auth]")) {
+    radio.addEventListener("change", () => {
+      const apikey = radio.value === "apikey" && radio.checked;
+      $("apikey-fields").hidden = !apikey;
+      $("basic-fields").hidden = apikey;
+    });
+  }
+
+  const dropzone = $("dropzone");
+  dropzone.addEventListener("dragover", (e) => {
+    e.preventDefault();
+    dropzone.classList.add("active");
+  });
+  dropzone.addEventListener("dragleave", () => dropzone.classList.remove("active"));
+  dropzone.addEventListener("drop", (e) => {
+    e.preventDefault();
+    dropzone.classList.remove("active");
+    handleFiles(e.dataTransfer.files);
+  });
+  $("browse").addEventListener("click", () => $("files").click());
+  $("files").addEventListener("change", (e) => {
+    handleFiles(e.target.files);
+    e.target.value = "";
+  });
+  $("clear").addEventListener("click", () => {
+    localStorage.removeItem(RECENT_KEY);
+    renderRecent();
+  });
+
+  fetch("config.json")
+    .then((res) => res.json())
+    .then((cfg) => { maxUploadBytes = cfg.maxUploadBytes || maxUploadBytes; })
+    .catch(() => {})
+    .finally(() => { $("limit").textContent = `Up to ${formatBytes(maxUploadBytes)} per file.`; });
+
+  renderRecent();
+}
+
+init();
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+//go:embed static
+var static embed.FS
+
+// Prefix is the path the UI is served under.
+const Prefix = "/ui/"
+
+// Config is passed to the browser so its checks match the server's.
+type Config struct {
+	MaxUploadBytes int64 `json:"maxUploadBytes"`
+}
+
+// Handler serves the upload UI and its config under Prefix. The UI calls the
+// API from the browser, so it needs no credentials of its own.
+func Handler(cfg Config) http.Handler {
+	files, _ := fs.Sub(static, "static")
+	fileServer := http.StripPrefix(Prefix, http.FileServerFS(files))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+Prefix+"config.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(cfg)
+	})
+	mux.HandleFunc("GET "+Prefix, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		fileServer.ServeHTTP(w, r)
+	})
+	mux.Handle("GET "+strings.TrimSuffix(Prefix, "/"), http.RedirectHandler(Prefix, http.StatusMovedPermanently))
+	return mux
+}
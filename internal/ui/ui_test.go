@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler(Config{MaxUploadBytes: 1024}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestServesIndex(t *testing.T) {
+	rec := get(t, "/ui/")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "<title>File upload</title>")
+	require.NotEmpty(t, rec.Header().Get("Content-Security-Policy"))
+}
+
+func TestServesAssets(t *testing.T) {
+	rec := get(t, "/ui/app.js")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get("Content-Type"), "javascript")
+}
+
+func TestServesConfig(t *testing.T) {
+	rec := get(t, "/ui/config.json")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"maxUploadBytes":1024}`, rec.Body.String())
+}
+
+func TestRedirectsBarePath(t *testing.T) {
+	rec := get(t, "/ui")
+	require.Equal(t, http.StatusMovedPermanently, rec.Code)
+	require.Equal(t, "/ui/", rec.Header().Get("Location"))
+}
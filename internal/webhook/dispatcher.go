@@ -0,0 +1,292 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/spool"
+)
+
+const (
+	defaultMaxAttempts = 10
+	defaultBackoffBase = 10 * time.Second
+	defaultBackoffMax  = time.Hour
+	defaultTimeout     = 10 * time.Second
+)
+
+// Config tunes delivery. Zero values use the defaults.
+type Config struct {
+	// QueueDir holds pending and dead-lettered deliveries.
+	QueueDir string
+	// MaxAttempts is how often a delivery is tried before it is dead-lettered.
+	MaxAttempts int
+	// BackoffBase is the wait after the first failure, doubling per attempt
+	// up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Timeout bounds each request.
+	Timeout time.Duration
+}
+
+// Dispatcher queues upload events for each matching subscription and
+// delivers them in the background, each subscription independently so a
+// slow or dead receiver does not hold up the others.
+type Dispatcher struct {
+	cfg    Config
+	subs   map[string]*subscriber
+	queue  *queue
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// subscriber holds the pending deliveries of one subscription. The queue on
+// disk is only read at startup; afterwards pending mirrors it.
+type subscriber struct {
+	sub    Subscription
+	client *fileupload.WebhookClient
+
+	// mu guards pending, oldest first.
+	mu      sync.Mutex
+	pending []Delivery
+	wake    chan struct{}
+}
+
+// New creates a client per subscription and loads the deliveries left in
+// the queue by a previous run. Deliveries for subscriptions that no longer
+// exist are dead-lettered.
+func New(cfg Config, subs []Subscription, logger *slog.Logger) (*Dispatcher, error) {
+	if cfg.QueueDir == "" {
+		return nil, errors.New("webhook queue dir is required")
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = defaultBackoffBase
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = defaultBackoffMax
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	q, err := newQueue(cfg.QueueDir)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dispatcher{
+		cfg:    cfg,
+		subs:   map[string]*subscriber{},
+		queue:  q,
+		logger: logger,
+		now:    time.Now,
+	}
+	for _, sub := range subs {
+		client, err := fileupload.NewWebhookClient(fileupload.WithClient(&http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &signingTransport{
+				secret: []byte(sub.Secret),
+				next:   http.DefaultTransport,
+				now:    func() time.Time { return d.now() },
+			},
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("creating webhook client for %s: %w", sub.Name, err)
+		}
+		d.subs[sub.Name] = &subscriber{sub: sub, client: client, wake: make(chan struct{}, 1)}
+	}
+
+	pending, err := spool.Load[Delivery](q.pending)
+	if err != nil {
+		return nil, fmt.Errorf("loading webhook queue: %w", err)
+	}
+	for _, delivery := range pending {
+		if s, ok := d.subs[delivery.Subscription]; ok {
+			s.pending = append(s.pending, delivery)
+			continue
+		}
+		delivery.LastError = fmt.Sprintf("subscription %q no longer exists", delivery.Subscription)
+		if err := q.pending.Move(q.dead, delivery.ID, delivery); err != nil {
+			return nil, fmt.Errorf("dead-lettering delivery: %w", err)
+		}
+		logger.Warn("webhook dead-lettered", "subscription", delivery.Subscription, "delivery", delivery.ID, "error", delivery.LastError)
+	}
+	return d, nil
+}
+
+// Publish queues an upload.completed event for every subscription matching
+// the uploaded object. Delivery happens in Run.
+func (d *Dispatcher) Publish(ctx context.Context, uploader string, upload fileupload.UploadResponse) error {
+	event := &fileupload.UploadEvent{
+		ID:       spool.NewID(d.now()),
+		Type:     fileupload.UploadEventTypeUploadCompleted,
+		Time:     d.now().UTC(),
+		Uploader: uploader,
+		Upload:   upload,
+	}
+
+	var errs error
+	for name, s := range d.subs {
+		if !s.sub.matches(upload.Filename) {
+			continue
+		}
+		delivery := Delivery{
+			ID:           event.ID + "-" + name,
+			Subscription: name,
+			Event:        event,
+			NextAttempt:  event.Time,
+		}
+
+		s.mu.Lock()
+		err := d.queue.pending.Put(delivery.ID, delivery)
+		if err == nil {
+			s.pending = append(s.pending, delivery)
+		}
+		s.mu.Unlock()
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return errs
+}
+
+// Run delivers queued events until ctx is done, one worker per
+// subscription.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, s := range d.subs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.run(ctx, s)
+		}()
+	}
+	wg.Wait()
+}
+
+// DeadLetters returns the deliveries that ran out of attempts, oldest first.
+func (d *Dispatcher) DeadLetters() ([]Delivery, error) {
+	return spool.Load[Delivery](d.queue.dead)
+}
+
+// run delivers what is due for s, then sleeps until the next retry or a new
+// delivery.
+func (d *Dispatcher) run(ctx context.Context, s *subscriber) {
+	for {
+		var retry <-chan time.Time
+		if next := d.deliverDue(ctx, s); !next.IsZero() {
+			retry = time.After(next.Sub(d.now()))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-retry:
+		case <-s.wake:
+		}
+	}
+}
+
+// deliverDue tries each due delivery of s once, oldest first, and returns
+// when the earliest remaining delivery is due, or zero if none are left.
+func (d *Dispatcher) deliverDue(ctx context.Context, s *subscriber) time.Time {
+	s.mu.Lock()
+	pending := slices.Clone(s.pending)
+	s.mu.Unlock()
+
+	for _, delivery := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		if delivery.NextAttempt.After(d.now()) {
+			continue
+		}
+		if err := d.deliver(ctx, s, delivery); err != nil {
+			d.logger.ErrorContext(ctx, "updating webhook queue failed", "delivery", delivery.ID, "error", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, delivery := range s.pending {
+		if next.IsZero() || delivery.NextAttempt.Before(next) {
+			next = delivery.NextAttempt
+		}
+	}
+	return next
+}
+
+// deliver sends one delivery and records the result in the queue.
+func (d *Dispatcher) deliver(ctx context.Context, s *subscriber, delivery Delivery) error {
+	_, sendErr := s.client.UploadCompleted(ctx, s.sub.URL, delivery.Event)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sendErr == nil {
+		d.logger.InfoContext(ctx, "webhook delivered",
+			"subscription", delivery.Subscription,
+			"event", delivery.Event.ID,
+			"attempts", delivery.Attempts+1,
+		)
+		s.drop(delivery.ID)
+		return d.queue.pending.Remove(delivery.ID)
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the attempt does not count.
+		return nil
+	}
+
+	delivery.Attempts++
+	delivery.LastAttempt = d.now().UTC()
+	delivery.LastError = sendErr.Error()
+
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		d.logger.ErrorContext(ctx, "webhook dead-lettered",
+			"subscription", delivery.Subscription,
+			"event", delivery.Event.ID,
+			"attempts", delivery.Attempts,
+			"error", sendErr,
+		)
+		s.drop(delivery.ID)
+		return d.queue.pending.Move(d.queue.dead, delivery.ID, delivery)
+	}
+
+	delivery.NextAttempt = delivery.LastAttempt.Add(spool.Backoff(delivery.Attempts, d.cfg.BackoffBase, d.cfg.BackoffMax))
+	d.logger.WarnContext(ctx, "webhook delivery failed",
+		"subscription", delivery.Subscription,
+		"event", delivery.Event.ID,
+		"attempts", delivery.Attempts,
+		"next_attempt", delivery.NextAttempt,
+		"error", sendErr,
+	)
+	if i := s.index(delivery.ID); i >= 0 {
+		s.pending[i] = delivery
+	}
+	return d.queue.pending.Put(delivery.ID, delivery)
+}
+
+func (s *subscriber) index(id string) int {
+	return slices.IndexFunc(s.pending, func(d Delivery) bool { return d.ID == id })
+}
+
+func (s *subscriber) drop(id string) {
+	if i := s.index(id); i >= 0 {
+		s.pending = slices.Delete(s.pending, i, i+1)
+	}
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/spool"
+)
+
+// Delivery is one event queued for one subscription.
+type Delivery struct {
+	ID           string                  `json:"id"`
+	Subscription string                  `json:"subscription"`
+	Event        *fileupload.UploadEvent `json:"event"`
+	Attempts     int                     `json:"attempts"`
+	NextAttempt  time.Time               `json:"nextAttempt"`
+	LastAttempt  time.Time               `json:"lastAttempt,omitzero"`
+	LastError    string                  `json:"lastError,omitempty"`
+}
+
+// queue keeps deliveries in the pending spool until they are delivered and
+// in the dead spool once they have run out of attempts, so deliveries
+// survive restarts.
+type queue struct {
+	pending *spool.Dir
+	dead    *spool.Dir
+}
+
+func newQueue(dir string) (*queue, error) {
+	pending, err := spool.Open(filepath.Join(dir, "pending"))
+	if err != nil {
+		return nil, fmt.Errorf("opening webhook queue: %w", err)
+	}
+	dead, err := spool.Open(filepath.Join(dir, "dead"))
+	if err != nil {
+		return nil, fmt.Errorf("opening webhook queue: %w", err)
+	}
+	return &queue{pending: pending, dead: dead}, nil
+}
@@ -0,0 +1,83 @@
+// Package webhook notifies subscribers of completed uploads with signed
+// HTTP callbacks, retrying failed deliveries from a persistent queue.
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidSubscription = errors.New("invalid webhook subscription")
+
+// Subscription receives an event for every upload under one of Prefixes, or
+// for every upload when Prefixes is empty.
+type Subscription struct {
+	Name     string   `yaml:"name"`
+	URL      string   `yaml:"url"`
+	Secret   string   `yaml:"secret"`
+	Prefixes []string `yaml:"prefixes"`
+}
+
+type subscriptionsFile struct {
+	Subscriptions []Subscription `yaml:"subscriptions"`
+}
+
+// LoadSubscriptions reads and validates a subscriptions file.
+func LoadSubscriptions(path string) ([]Subscription, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading webhook subscriptions: %w", err)
+	}
+
+	var parsed subscriptionsFile
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing webhook subscriptions: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for i, sub := range parsed.Subscriptions {
+		if err := sub.validate(); err != nil {
+			return nil, fmt.Errorf("subscription %d: %w", i, err)
+		}
+		if seen[sub.Name] {
+			return nil, fmt.Errorf("%w: duplicate name %q", ErrInvalidSubscription, sub.Name)
+		}
+		seen[sub.Name] = true
+	}
+	return parsed.Subscriptions, nil
+}
+
+func (s Subscription) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalidSubscription)
+	}
+	// Names are part of the queue's file names.
+	if strings.ContainsAny(s.Name, `/\.`) {
+		return fmt.Errorf("%w: %s: name must not contain '/', '\\' or '.'", ErrInvalidSubscription, s.Name)
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s: url must be an absolute http(s) url", ErrInvalidSubscription, s.Name)
+	}
+	if s.Secret == "" {
+		return fmt.Errorf("%w: %s: missing secret", ErrInvalidSubscription, s.Name)
+	}
+	return nil
+}
+
+func (s Subscription) matches(objectName string) bool {
+	if len(s.Prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range s.Prefixes {
+		if strings.HasPrefix(objectName, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/totalprocessing/file-upload/internal/fileupload"
+	"gitlab.com/totalprocessing/file-upload/internal/spool"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"id":"1"}`)
+	now := time.Now()
+
+	header := Sign(secret, now, body)
+	require.NoError(t, Verify(secret, header, body, time.Minute))
+
+	require.ErrorIs(t, Verify([]byte("other"), header, body, time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, header, []byte(`{"id":"2"}`), time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, Sign(secret, now.Add(-time.Hour), body), body, time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, "garbage", body, time.Minute), ErrInvalidSignature)
+}
+
+func TestLoadSubscriptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+subscriptions:
+  - name: a
+    url: https://example.com/a
+    secret: s
+    prefixes: [partner-a/]
+  - name: a
+    url: https://example.com/b
+    secret: s
+`), 0o600))
+
+	_, err := LoadSubscriptions(path)
+	require.ErrorIs(t, err, ErrInvalidSubscription)
+
+	sub := Subscription{Prefixes: []string{"partner-a/"}}
+	require.True(t, sub.matches("partner-a/report.csv"))
+	require.False(t, sub.matches("partner-b/report.csv"))
+	require.True(t, Subscription{}.matches("anything.csv"))
+}
+
+func newTestDispatcher(t *testing.T, url string, maxAttempts int) *Dispatcher {
+	t.Helper()
+	d, err := New(Config{
+		QueueDir:    t.TempDir(),
+		MaxAttempts: maxAttempts,
+		BackoffBase: time.Second,
+		BackoffMax:  4 * time.Second,
+	}, []Subscription{{Name: "test", URL: url, Secret: "secret"}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return d
+}
+
+func TestDeliverSignsEvent(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify([]byte("secret"), r.Header.Get(SignatureHeader), body, time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := newTestDispatcher(t, srv.URL, 3)
+	require.NoError(t, d.Publish(context.Background(), "partner-a", fileupload.UploadResponse{Filename: "report.csv"}))
+
+	d.deliverDue(context.Background(), d.subs["test"])
+	require.Len(t, received, 1)
+
+	pending, err := spool.Load[Delivery](d.queue.pending)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestDeliverRetriesThenDeadLetters(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	d := newTestDispatcher(t, srv.URL, 3)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	require.NoError(t, d.Publish(context.Background(), "partner-a", fileupload.UploadResponse{Filename: "report.csv"}))
+
+	d.deliverDue(context.Background(), d.subs["test"])
+	pending, err := spool.Load[Delivery](d.queue.pending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, 1, pending[0].Attempts)
+	require.Equal(t, now.Add(time.Second).UTC(), pending[0].NextAttempt.UTC())
+
+	// Not due yet.
+	d.deliverDue(context.Background(), d.subs["test"])
+	require.EqualValues(t, 1, calls.Load())
+
+	now = now.Add(time.Second)
+	d.deliverDue(context.Background(), d.subs["test"])
+	pending, err = spool.Load[Delivery](d.queue.pending)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(2*time.Second).UTC(), pending[0].NextAttempt.UTC())
+
+	now = now.Add(2 * time.Second)
+	d.deliverDue(context.Background(), d.subs["test"])
+	require.EqualValues(t, 3, calls.Load())
+
+	pending, err = spool.Load[Delivery](d.queue.pending)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	dead, err := d.DeadLetters()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, 3, dead[0].Attempts)
+	require.Equal(t, "partner-a", dead[0].Event.Uploader)
+	require.NotEmpty(t, dead[0].LastError)
+}
+
+func TestSlowSubscriberDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	received := make(chan struct{}, 1)
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer healthy.Close()
+
+	d, err := New(Config{QueueDir: t.TempDir(), Timeout: time.Minute}, []Subscription{
+		{Name: "hanging", URL: hanging.URL, Secret: "secret"},
+		{Name: "healthy", URL: healthy.URL, Secret: "secret"},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	require.NoError(t, d.Publish(ctx, "partner-a", fileupload.UploadResponse{Filename: "report.csv"}))
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("healthy subscriber waited for the hanging one")
+	}
+}
+
+func TestNewLoadsQueueFromPreviousRun(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	subs := []Subscription{{Name: "a", URL: "http://127.0.0.1:0", Secret: "s"}, {Name: "b", URL: "http://127.0.0.1:0", Secret: "s"}}
+
+	first, err := New(Config{QueueDir: dir}, subs, logger)
+	require.NoError(t, err)
+	require.NoError(t, first.Publish(context.Background(), "partner-a", fileupload.UploadResponse{Filename: "report.csv"}))
+
+	second, err := New(Config{QueueDir: dir}, subs[:1], logger)
+	require.NoError(t, err)
+	require.Len(t, second.subs["a"].pending, 1)
+
+	dead, err := second.DeadLetters()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, "b", dead[0].Subscription)
+	require.Contains(t, dead[0].LastError, "no longer exists")
+}